@@ -5,27 +5,128 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"time"
 
 	"github.com/aliyun/alibabacloud-oss-go-sdk-v2/oss"
 	"github.com/aliyun/alibabacloud-oss-go-sdk-v2/oss/credentials"
 	"github.com/mini-tiger/fast-api/config"
 	"github.com/mini-tiger/fast-api/core"
+	"github.com/mini-tiger/fast-api/dError"
 )
 
+// ConfigType [aliOss] 配置，命名实例为 [aliOss.<name>]，未配置的项继承 [aliOss]
+type ConfigType struct {
+	BucketName      string `ini:"bucketName" required:"true"`
+	CdnHost         string `ini:"cdnHost"`
+	Endpoint        string `ini:"endpoint" required:"true"`
+	Region          string `ini:"region" required:"true"`
+	AccessKeyId     string `ini:"accessKeyId" required:"true"`
+	AccessKeySecret string `ini:"accessKeySecret" required:"true"`
+}
+
+// GetConfig 读取并校验 [aliOss] 配置
+func GetConfig() (*ConfigType, error) {
+	return GetNamedConfig("")
+}
+
+// GetNamedConfig 读取并校验命名实例 [aliOss.<name>] 的配置，name 为空时为 [aliOss]
+func GetNamedConfig(name string) (*ConfigType, error) {
+	ossConfig := &ConfigType{}
+	section := config.SectionName("aliOss", name)
+	if "" != name && !config.HasSection(section) {
+		return ossConfig, dError.NewError(fmt.Sprintf("缺少配置分组 [%s]", section))
+	}
+	return ossConfig, config.Bind(section, ossConfig)
+}
+
+// validate 校验默认实例及全部命名实例的配置
+func validate() error {
+	var errList []error
+	for _, name := range append([]string{""}, config.Names("aliOss")...) {
+		if _, err := GetNamedConfig(name); nil != err {
+			errList = append(errList, err)
+		}
+	}
+	if 0 < len(errList) {
+		return dError.NewError("OSS配置错误", errList...)
+	}
+	return nil
+}
+
+func init() {
+	core.RegisterComponent(&core.ComponentType{
+		Name:     core.ComponentOss,
+		Order:    40,
+		Depends:  []string{core.ComponentConfig},
+		Validate: validate,
+		Init:     Init,
+	})
+}
+
+// Init 校验默认实例及全部命名实例的OSS配置是否完整
+func Init() error {
+	if err := validate(); nil != err {
+		return err
+	}
+	for _, name := range append([]string{""}, config.Names("aliOss")...) {
+		healthCheckName := core.ComponentOss
+		if "" != name {
+			healthCheckName += "." + name
+		}
+		core.RegisterHealthCheck(healthCheckName, func(ctx context.Context) (any, error) {
+			return healthCheck(ctx, name)
+		})
+	}
+	return nil
+}
+
+// healthCheck 检查 Bucket 是否可访问
+func healthCheck(ctx context.Context, name string) (any, error) {
+	c, err := NewNamed(name)
+	if nil != err {
+		return nil, err
+	}
+	// 开发环境用外网
+	c.SetUseInternalEndpoint(core.Mode != core.Dev)
+	exist, err := c.GetClient().IsBucketExist(ctx, c.bucketName)
+	if nil != err {
+		return nil, err
+	}
+	if !exist {
+		return nil, fmt.Errorf("Bucket %s 不存在", c.bucketName)
+	}
+	return map[string]string{"bucket": c.bucketName}, nil
+}
+
 type ClientType struct {
 	cdnHost             string
 	bucketName          string
+	config              *ConfigType
 	client              *oss.Client
 	useInternalEndpoint bool
 }
 
+// New 按 [aliOss] 配置创建客户端，配置错误在启动校验时报告
 func New() *ClientType {
-	ossConfig := config.GetInstance().Section("aliOss")
+	ossConfig, _ := GetConfig()
+	return newClient(ossConfig)
+}
+
+// NewNamed 按命名实例 [aliOss.<name>] 的配置创建客户端，name 为空时为 [aliOss]；
+// 配置错误时返回错误，如实例名称拼写错误
+func NewNamed(name string) (*ClientType, error) {
+	ossConfig, err := GetNamedConfig(name)
+	if nil != err {
+		return nil, err
+	}
+	return newClient(ossConfig), nil
+}
+
+func newClient(ossConfig *ConfigType) *ClientType {
 	return &ClientType{
-		bucketName: ossConfig.Key("bucketName").Value(),
-		cdnHost:    ossConfig.Key("cdnHost").Value(),
+		bucketName: ossConfig.BucketName,
+		cdnHost:    ossConfig.CdnHost,
+		config:     ossConfig,
 	}
 }
 
@@ -33,18 +134,12 @@ func (c *ClientType) GetClient() *oss.Client {
 	if c.client != nil {
 		return c.client
 	}
-	ossConfig := config.GetInstance().Section("aliOss")
-	endpoint := ossConfig.Key("endpoint").Value()
-	region := ossConfig.Key("region").Value()
-	accessKeyID := ossConfig.Key("accessKeyId").Value()
-	accessKeySecret := ossConfig.Key("accessKeySecret").Value()
-
-	_ = os.Setenv("OSS_ACCESS_KEY_ID", accessKeyID)
-	_ = os.Setenv("OSS_ACCESS_KEY_SECRET", accessKeySecret)
+	endpoint := c.config.Endpoint
+	region := c.config.Region
 
-	// 方式二：同时填写Region和Endpoint
+	// 每个实例使用各自的 AccessKey，不能通过进程环境变量传递
 	cfg := oss.LoadDefaultConfig().
-		WithCredentialsProvider(credentials.NewEnvironmentVariableCredentialsProvider()).
+		WithCredentialsProvider(credentials.NewStaticCredentialsProvider(c.config.AccessKeyId, c.config.AccessKeySecret)).
 		WithRegion(region).     // 填写Bucket所在地域
 		WithEndpoint(endpoint). // 填写Bucket所在地域对应的公网Endpoint
 		WithUseInternalEndpoint(c.useInternalEndpoint)
@@ -66,8 +161,9 @@ func (c *ClientType) UploadFile(localFilePath, dir, fileName string, metadata ma
 		c.SetUseInternalEndpoint(false)
 	}
 	// 获取本地文件名
-	serverName := config.GetInstance().Section("core").Key("serverName").Value()
-	cdnHost := config.GetInstance().Section("aliOss").Key("cdnHost").Value()
+	coreConfig, _ := config.GetCoreConfig()
+	serverName := coreConfig.ServerName
+	cdnHost := c.cdnHost
 
 	date := time.Now().Format("200601/02")
 	unixMicro := time.Now().UnixMicro()
@@ -101,8 +197,9 @@ func (c *ClientType) UploadFileStream(url, dir, fileName string, metadata map[st
 		c.SetUseInternalEndpoint(false)
 	}
 	// 获取本地文件名
-	serverName := config.GetInstance().Section("core").Key("serverName").Value()
-	cdnHost := config.GetInstance().Section("aliOss").Key("cdnHost").Value()
+	coreConfig, _ := config.GetCoreConfig()
+	serverName := coreConfig.ServerName
+	cdnHost := c.cdnHost
 
 	date := time.Now().Format("200601/02")
 	unixMicro := time.Now().UnixMicro()
@@ -148,8 +245,9 @@ func (c *ClientType) GetPreUploadFileUrl(dir, fileName string, metadata map[stri
 	c.SetUseInternalEndpoint(false)
 
 	// 获取本地文件名
-	serverName := config.GetInstance().Section("core").Key("serverName").Value()
-	cdnHost := config.GetInstance().Section("aliOss").Key("cdnHost").Value()
+	coreConfig, _ := config.GetCoreConfig()
+	serverName := coreConfig.ServerName
+	cdnHost := c.cdnHost
 
 	date := time.Now().Format("200601/02")
 	unixMicro := time.Now().UnixMicro()
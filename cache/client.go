@@ -0,0 +1,288 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ClientType 命名的Redis实例，方法与同名的包级函数一致，包级函数使用默认实例 [redis]
+type ClientType struct {
+	name string
+}
+
+// Use 获取命名实例 [redis.<name>]，name 为空时为默认实例 [redis]；连接在首次使用时创建
+func Use(name string) *ClientType {
+	return &ClientType{name: name}
+}
+
+// Client 获取Redis客户端，未创建时按配置创建（不测试连通性），配置错误会 panic
+func (c *ClientType) Client() *redis.Client {
+	client, err := newClient(c.name)
+	if nil != err {
+		panic(err)
+	}
+	return client
+}
+
+// SetWithExpire 设置键值对，并指定过期时间
+// 支持字符串、数字、布尔值、切片、结构体、map等类型
+// 对于复杂类型（切片、结构体、map），会自动使用JSON序列化
+func (c *ClientType) SetWithExpire(key string, value interface{}, expiration time.Duration) error {
+	data, err := serializeValue(value)
+	if err != nil {
+		return fmt.Errorf("序列化值失败: %v", err)
+	}
+	return c.Client().Set(ctx, key, data, expiration).Err()
+}
+
+// Get 获取字符串值（向后兼容，返回原始字符串）
+func (c *ClientType) Get(key string) (string, error) {
+	result, err := c.Client().Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", fmt.Errorf("键 %s 不存在", key)
+	}
+	return result, err
+}
+
+// GetObject 获取值并反序列化到指定类型
+// 支持字符串、数字、布尔值、切片、结构体、map等类型
+// 示例: var user User; err := GetObject("user:1", &user)
+func (c *ClientType) GetObject(key string, dest interface{}) error {
+	data, err := c.Client().Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return fmt.Errorf("键 %s 不存在", key)
+	}
+	if err != nil {
+		return err
+	}
+
+	return deserializeValue(data, dest)
+}
+
+// GetBytes 获取字节数组值
+func (c *ClientType) GetBytes(key string) ([]byte, error) {
+	result, err := c.Client().Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("键 %s 不存在", key)
+	}
+	return result, err
+}
+
+// Delete 删除一个或多个键
+func (c *ClientType) Delete(keys ...string) error {
+	return c.Client().Del(ctx, keys...).Err()
+}
+
+// Exists 检查键是否存在
+func (c *ClientType) Exists(key string) (bool, error) {
+	count, err := c.Client().Exists(ctx, key).Result()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Expire 设置键的过期时间
+func (c *ClientType) Expire(key string, expiration time.Duration) error {
+	return c.Client().Expire(ctx, key, expiration).Err()
+}
+
+// TTL 获取键的剩余过期时间（秒）
+func (c *ClientType) TTL(key string) (time.Duration, error) {
+	return c.Client().TTL(ctx, key).Result()
+}
+
+// Increment 将键的值增加1
+func (c *ClientType) Increment(key string) (int64, error) {
+	return c.Client().Incr(ctx, key).Result()
+}
+
+// IncrementBy 将键的值增加指定数值
+func (c *ClientType) IncrementBy(key string, value int64) (int64, error) {
+	return c.Client().IncrBy(ctx, key, value).Result()
+}
+
+// Decrement 将键的值减少1
+func (c *ClientType) Decrement(key string) (int64, error) {
+	return c.Client().Decr(ctx, key).Result()
+}
+
+// DecrementBy 将键的值减少指定数值
+func (c *ClientType) DecrementBy(key string, value int64) (int64, error) {
+	return c.Client().DecrBy(ctx, key, value).Result()
+}
+
+// HSet 设置哈希字段值
+func (c *ClientType) HSet(key string, field string, value interface{}) error {
+	return c.Client().HSet(ctx, key, field, value).Err()
+}
+
+// HGet 获取哈希字段值
+func (c *ClientType) HGet(key string, field string) (string, error) {
+	result, err := c.Client().HGet(ctx, key, field).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", fmt.Errorf("哈希字段 %s.%s 不存在", key, field)
+	}
+	return result, err
+}
+
+// HGetAll 获取哈希的所有字段和值
+func (c *ClientType) HGetAll(key string) (map[string]string, error) {
+	return c.Client().HGetAll(ctx, key).Result()
+}
+
+// HDel 删除哈希的一个或多个字段
+func (c *ClientType) HDel(key string, fields ...string) error {
+	return c.Client().HDel(ctx, key, fields...).Err()
+}
+
+// LPush 从列表左侧推入元素
+func (c *ClientType) LPush(key string, values ...interface{}) error {
+	return c.Client().LPush(ctx, key, values...).Err()
+}
+
+// RPush 从列表右侧推入元素
+func (c *ClientType) RPush(key string, values ...interface{}) error {
+	return c.Client().RPush(ctx, key, values...).Err()
+}
+
+// LPop 从列表左侧弹出元素
+func (c *ClientType) LPop(key string) (string, error) {
+	result, err := c.Client().LPop(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", fmt.Errorf("列表 %s 为空", key)
+	}
+	return result, err
+}
+
+// RPop 从列表右侧弹出元素
+func (c *ClientType) RPop(key string) (string, error) {
+	result, err := c.Client().RPop(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", fmt.Errorf("列表 %s 为空", key)
+	}
+	return result, err
+}
+
+// LRange 获取列表指定范围内的元素
+func (c *ClientType) LRange(key string, start, stop int64) ([]string, error) {
+	return c.Client().LRange(ctx, key, start, stop).Result()
+}
+
+// SAdd 向集合添加成员
+func (c *ClientType) SAdd(key string, members ...interface{}) error {
+	return c.Client().SAdd(ctx, key, members...).Err()
+}
+
+// SMembers 获取集合的所有成员
+func (c *ClientType) SMembers(key string) ([]string, error) {
+	return c.Client().SMembers(ctx, key).Result()
+}
+
+// SIsMember 检查成员是否在集合中
+func (c *ClientType) SIsMember(key string, member interface{}) (bool, error) {
+	return c.Client().SIsMember(ctx, key, member).Result()
+}
+
+// SRem 从集合中移除成员
+func (c *ClientType) SRem(key string, members ...interface{}) error {
+	return c.Client().SRem(ctx, key, members...).Err()
+}
+
+// Keys 根据模式查找所有匹配的键
+func (c *ClientType) Keys(pattern string) ([]string, error) {
+	return c.Client().Keys(ctx, pattern).Result()
+}
+
+// DeleteByPattern 使用 SCAN 遍历并删除匹配的键，避免 KEYS 阻塞Redis，返回删除数量
+func (c *ClientType) DeleteByPattern(pattern string) (int64, error) {
+	var count int64
+	var cursor uint64
+	for {
+		keyList, nextCursor, err := c.Client().Scan(ctx, cursor, pattern, 500).Result()
+		if err != nil {
+			return count, err
+		}
+		if 0 < len(keyList) {
+			deleted, err := c.Client().Del(ctx, keyList...).Result()
+			if err != nil {
+				return count, err
+			}
+			count += deleted
+		}
+		if 0 == nextCursor {
+			return count, nil
+		}
+		cursor = nextCursor
+	}
+}
+
+// FlushDB 清空当前数据库
+func (c *ClientType) FlushDB() error {
+	return c.Client().FlushDB(ctx).Err()
+}
+
+// Lock 非阻塞锁，获取成功返回 true
+func (c *ClientType) Lock(key string, value any, expiration time.Duration) (bool, error) {
+	result, err := c.Client().SetNX(ctx, key, value, expiration).Result()
+	if err != nil {
+		return false, err
+	}
+	return result, nil
+}
+
+func (c *ClientType) Unlock(key string) {
+	_ = c.Client().Del(ctx, key).Err()
+}
+
+// safeUnlockScript 锁的值一致时才释放，keep 大于 0 时改为 keep 毫秒后过期
+var safeUnlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+if tonumber(ARGV[2]) > 0 then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return redis.call("DEL", KEYS[1])
+`)
+
+// SafeUnlock 锁的值与加锁时一致才释放，避免锁过期后被其他实例获取时误删，返回是否释放；
+// keep 大于 0 时不立即删除而是 keep 后过期，用于吸收多实例之间的时钟误差
+func (c *ClientType) SafeUnlock(key string, value any, keep time.Duration) (bool, error) {
+	data, err := serializeValue(value)
+	if err != nil {
+		return false, err
+	}
+	result, err := safeUnlockScript.Run(ctx, c.Client(), []string{key}, data, keep.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return 1 == result, nil
+}
+
+// BlockingLock 阻塞锁：在 waitTimeout 内轮询获取锁，获取成功返回 true；超时返回 false, nil
+func (c *ClientType) BlockingLock(key string, lockExpiration time.Duration) (bool, error) {
+	return c.BlockingLockWithInterval(key, 1, lockExpiration, 60*time.Second, 50*time.Millisecond)
+}
+
+// BlockingLockWithInterval
+// key 锁键；value 锁的值（建议唯一，解锁时需传入同一 value 调用 SafeUnlock）；
+// lockExpiration 锁过期时间；waitTimeout 最长等待时间
+// 轮询间隔为 retryInterval，默认 50ms
+func (c *ClientType) BlockingLockWithInterval(key string, value interface{}, lockExpiration, waitTimeout, retryInterval time.Duration) (bool, error) {
+	deadline := time.Now().Add(waitTimeout)
+	for time.Now().Before(deadline) {
+		ok, err := c.Lock(key, value, lockExpiration)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+		time.Sleep(retryInterval)
+	}
+	return false, nil
+}
@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/mini-tiger/fast-api/command"
+	"github.com/mini-tiger/fast-api/core"
+	"github.com/mini-tiger/fast-api/dError"
+)
+
+func init() {
+	command.Register("cache flush", &command.CommandType{
+		Usage:      "删除匹配的缓存键",
+		Components: []string{core.ComponentRedis},
+		Flags: func(flagSet *flag.FlagSet) {
+			flagSet.String("pattern", "", "键的匹配模式，如 user:*")
+			flagSet.Bool("all", false, "清空当前数据库")
+			flagSet.String("name", "", "命名实例，如 session 对应 [redis.session]，默认为 [redis]")
+		},
+		Run: func(c *command.ContextType) error {
+			client := Use(c.String("name"))
+			if c.Bool("all") {
+				return client.FlushDB()
+			}
+			pattern := c.String("pattern")
+			if "" == pattern {
+				return dError.NewError("请指定 --pattern 或 --all")
+			}
+			count, err := client.DeleteByPattern(pattern)
+			_, _ = fmt.Fprintf(c.Out, "已删除 %d 个键\n", count)
+			return err
+		},
+	})
+}
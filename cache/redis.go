@@ -7,57 +7,173 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/mini-tiger/fast-api/config"
+	"github.com/mini-tiger/fast-api/core"
 	"github.com/mini-tiger/fast-api/dError"
 	"github.com/redis/go-redis/v9"
 )
 
-var redisClient *redis.Client
+// clientMap 已创建的Redis客户端，键为实例名称，默认实例为空字符串
+var clientMap = map[string]*redis.Client{}
 var ctx = context.Background()
+var lock sync.Mutex
+
+// ConfigType [redis] 配置，命名实例为 [redis.<name>]，未配置的项继承 [redis]
+type ConfigType struct {
+	Host     string `ini:"host" default:"localhost"`
+	Port     int    `ini:"port" default:"6379" range:"1,65535"`
+	Password string `ini:"password"`
+	// Password1、Password2 密码中含有 # 时分两段配置，连接时用 # 拼接；
+	// 兼容旧配置，新配置可以改用 password = ${file:/run/secrets/redis} 或 config encrypt 生成的 ENC(...)
+	Password1 string `ini:"password1"`
+	Password2 string `ini:"password2"`
+	Db        int    `ini:"db" default:"0" range:"0,"`
+}
+
+// GetConfig 读取并校验 [redis] 配置
+func GetConfig() (*ConfigType, error) {
+	return GetNamedConfig("")
+}
+
+// GetNamedConfig 读取并校验命名实例 [redis.<name>] 的配置，name 为空时为 [redis]
+func GetNamedConfig(name string) (*ConfigType, error) {
+	redisConfig := &ConfigType{}
+	section := config.SectionName("redis", name)
+	if "" != name && !config.HasSection(section) {
+		return redisConfig, dError.NewError(fmt.Sprintf("缺少配置分组 [%s]", section))
+	}
+	return redisConfig, config.Bind(section, redisConfig)
+}
+
+// GetPassword 连接使用的密码
+func (c *ConfigType) GetPassword() string {
+	if "" == c.Password && ("" != c.Password1 || "" != c.Password2) {
+		return c.Password1 + "#" + c.Password2
+	}
+	return c.Password
+}
+
+// validate 校验默认实例（配置了 [redis] 时）及全部命名实例的配置
+func validate() error {
+	var errList []error
+	for _, name := range instanceNames() {
+		if _, err := GetNamedConfig(name); nil != err {
+			errList = append(errList, err)
+		}
+	}
+	if 0 < len(errList) {
+		return dError.NewError("Redis配置错误", errList...)
+	}
+	return nil
+}
 
 func init() {
-	redisConfig := config.GetInstance().Section("redis")
-	host := redisConfig.Key("host").Value()
-	port := redisConfig.Key("port").Value()
-	password := redisConfig.Key("password").Value()
-	if len(password) == 0 {
-		password = redisConfig.Key("password1").Value() + "#" + redisConfig.Key("password2").Value()
+	core.RegisterComponent(&core.ComponentType{
+		Name:     core.ComponentRedis,
+		Order:    30,
+		Depends:  []string{core.ComponentConfig},
+		Validate: validate,
+		Init:     Init,
+		Stop: func(context.Context) error {
+			return Close()
+		},
+	})
+}
+
+// instanceNames 启动时需要连接的实例，没有 [redis] 分组时不连接默认实例，以便未使用Redis的应用正常启动
+func instanceNames() []string {
+	nameList := config.Names("redis")
+	if config.HasSection("redis") {
+		nameList = append([]string{""}, nameList...)
+	}
+	return nameList
+}
+
+// Init 创建默认实例（配置了 [redis] 时）及全部命名实例的Redis连接并测试连通性
+func Init() error {
+	if err := config.Load(); nil != err {
+		return err
+	}
+	for _, name := range instanceNames() {
+		client, err := newClient(name)
+		if nil != err {
+			return err
+		}
+		// 测试连接
+		_, err = client.Ping(ctx).Result()
+		if err != nil {
+			return dError.NewError(fmt.Sprintf("连接Redis [%s] 出错", config.SectionName("redis", name)), err)
+		}
+		healthCheckName := core.ComponentRedis
+		if "" != name {
+			healthCheckName += "." + name
+		}
+		core.RegisterHealthCheck(healthCheckName, func(checkCtx context.Context) (any, error) {
+			return healthCheck(checkCtx, name)
+		})
 	}
-	db := redisConfig.Key("db").MustInt(0)
+	return nil
+}
 
-	if host == "" {
-		host = "localhost"
+// healthCheck 执行 PING 并返回连接池统计
+func healthCheck(checkCtx context.Context, name string) (any, error) {
+	lock.Lock()
+	client := clientMap[name]
+	lock.Unlock()
+	if nil == client {
+		return nil, errors.New("Redis连接已关闭")
 	}
-	if port == "" {
-		port = "6379"
+	stats := client.PoolStats()
+	detail := map[string]any{
+		"total_conns": stats.TotalConns,
+		"idle_conns":  stats.IdleConns,
+		"hits":        stats.Hits,
+		"misses":      stats.Misses,
+		"timeouts":    stats.Timeouts,
 	}
+	return detail, client.Ping(checkCtx).Err()
+}
 
-	addr := fmt.Sprintf("%s:%s", host, port)
+// GetInstance 获取默认实例的Redis客户端，未创建时按配置创建（不测试连通性）
+func GetInstance() *redis.Client {
+	return Use("").Client()
+}
 
-	redisClient = redis.NewClient(&redis.Options{
-		Addr:     addr,
-		Password: password,
-		DB:       db,
-	})
+// newClient 按配置创建Redis客户端，已创建时直接返回
+func newClient(name string) (*redis.Client, error) {
+	lock.Lock()
+	defer lock.Unlock()
+	if client, ok := clientMap[name]; ok {
+		return client, nil
+	}
+	if err := config.Load(); nil != err {
+		return nil, err
+	}
 
-	// 测试连接
-	_, err := redisClient.Ping(ctx).Result()
-	if err != nil {
-		panic(dError.NewError("连接Redis出错", err))
+	redisConfig, err := GetNamedConfig(name)
+	if nil != err {
+		return nil, err
 	}
+
+	addr := fmt.Sprintf("%s:%d", redisConfig.Host, redisConfig.Port)
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: redisConfig.GetPassword(),
+		DB:       redisConfig.Db,
+	})
+	clientMap[name] = client
+	return client, nil
 }
 
 // SetWithExpire 设置键值对，并指定过期时间
 // 支持字符串、数字、布尔值、切片、结构体、map等类型
 // 对于复杂类型（切片、结构体、map），会自动使用JSON序列化
 func SetWithExpire(key string, value interface{}, expiration time.Duration) error {
-	data, err := serializeValue(value)
-	if err != nil {
-		return fmt.Errorf("序列化值失败: %v", err)
-	}
-	return redisClient.Set(ctx, key, data, expiration).Err()
+	return Use("").SetWithExpire(key, value, expiration)
 }
 
 // serializeValue 序列化值，对于复杂类型使用JSON，简单类型直接转换
@@ -111,26 +227,14 @@ func serializeValue(value interface{}) (string, error) {
 
 // Get 获取字符串值（向后兼容，返回原始字符串）
 func Get(key string) (string, error) {
-	result, err := redisClient.Get(ctx, key).Result()
-	if errors.Is(err, redis.Nil) {
-		return "", fmt.Errorf("键 %s 不存在", key)
-	}
-	return result, err
+	return Use("").Get(key)
 }
 
 // GetObject 获取值并反序列化到指定类型
 // 支持字符串、数字、布尔值、切片、结构体、map等类型
 // 示例: var user User; err := GetObject("user:1", &user)
 func GetObject(key string, dest interface{}) error {
-	data, err := redisClient.Get(ctx, key).Result()
-	if errors.Is(err, redis.Nil) {
-		return fmt.Errorf("键 %s 不存在", key)
-	}
-	if err != nil {
-		return err
-	}
-
-	return deserializeValue(data, dest)
+	return Use("").GetObject(key, dest)
 }
 
 // deserializeValue 反序列化值
@@ -218,181 +322,169 @@ func deserializeValue(data string, dest interface{}) error {
 
 // GetBytes 获取字节数组值
 func GetBytes(key string) ([]byte, error) {
-	result, err := redisClient.Get(ctx, key).Bytes()
-	if errors.Is(err, redis.Nil) {
-		return nil, fmt.Errorf("键 %s 不存在", key)
-	}
-	return result, err
+	return Use("").GetBytes(key)
 }
 
 // Delete 删除一个或多个键
 func Delete(keys ...string) error {
-	return redisClient.Del(ctx, keys...).Err()
+	return Use("").Delete(keys...)
 }
 
 // Exists 检查键是否存在
 func Exists(key string) (bool, error) {
-	count, err := redisClient.Exists(ctx, key).Result()
-	if err != nil {
-		return false, err
-	}
-	return count > 0, nil
+	return Use("").Exists(key)
 }
 
 // Expire 设置键的过期时间
 func Expire(key string, expiration time.Duration) error {
-	return redisClient.Expire(ctx, key, expiration).Err()
+	return Use("").Expire(key, expiration)
 }
 
 // TTL 获取键的剩余过期时间（秒）
 func TTL(key string) (time.Duration, error) {
-	return redisClient.TTL(ctx, key).Result()
+	return Use("").TTL(key)
 }
 
 // Increment 将键的值增加1
 func Increment(key string) (int64, error) {
-	return redisClient.Incr(ctx, key).Result()
+	return Use("").Increment(key)
 }
 
 // IncrementBy 将键的值增加指定数值
 func IncrementBy(key string, value int64) (int64, error) {
-	return redisClient.IncrBy(ctx, key, value).Result()
+	return Use("").IncrementBy(key, value)
 }
 
 // Decrement 将键的值减少1
 func Decrement(key string) (int64, error) {
-	return redisClient.Decr(ctx, key).Result()
+	return Use("").Decrement(key)
 }
 
 // DecrementBy 将键的值减少指定数值
 func DecrementBy(key string, value int64) (int64, error) {
-	return redisClient.DecrBy(ctx, key, value).Result()
+	return Use("").DecrementBy(key, value)
 }
 
 // HSet 设置哈希字段值
 func HSet(key string, field string, value interface{}) error {
-	return redisClient.HSet(ctx, key, field, value).Err()
+	return Use("").HSet(key, field, value)
 }
 
 // HGet 获取哈希字段值
 func HGet(key string, field string) (string, error) {
-	result, err := redisClient.HGet(ctx, key, field).Result()
-	if errors.Is(err, redis.Nil) {
-		return "", fmt.Errorf("哈希字段 %s.%s 不存在", key, field)
-	}
-	return result, err
+	return Use("").HGet(key, field)
 }
 
 // HGetAll 获取哈希的所有字段和值
 func HGetAll(key string) (map[string]string, error) {
-	return redisClient.HGetAll(ctx, key).Result()
+	return Use("").HGetAll(key)
 }
 
 // HDel 删除哈希的一个或多个字段
 func HDel(key string, fields ...string) error {
-	return redisClient.HDel(ctx, key, fields...).Err()
+	return Use("").HDel(key, fields...)
 }
 
 // LPush 从列表左侧推入元素
 func LPush(key string, values ...interface{}) error {
-	return redisClient.LPush(ctx, key, values...).Err()
+	return Use("").LPush(key, values...)
 }
 
 // RPush 从列表右侧推入元素
 func RPush(key string, values ...interface{}) error {
-	return redisClient.RPush(ctx, key, values...).Err()
+	return Use("").RPush(key, values...)
 }
 
 // LPop 从列表左侧弹出元素
 func LPop(key string) (string, error) {
-	result, err := redisClient.LPop(ctx, key).Result()
-	if errors.Is(err, redis.Nil) {
-		return "", fmt.Errorf("列表 %s 为空", key)
-	}
-	return result, err
+	return Use("").LPop(key)
 }
 
 // RPop 从列表右侧弹出元素
 func RPop(key string) (string, error) {
-	result, err := redisClient.RPop(ctx, key).Result()
-	if errors.Is(err, redis.Nil) {
-		return "", fmt.Errorf("列表 %s 为空", key)
-	}
-	return result, err
+	return Use("").RPop(key)
 }
 
 // LRange 获取列表指定范围内的元素
 func LRange(key string, start, stop int64) ([]string, error) {
-	return redisClient.LRange(ctx, key, start, stop).Result()
+	return Use("").LRange(key, start, stop)
 }
 
 // SAdd 向集合添加成员
 func SAdd(key string, members ...interface{}) error {
-	return redisClient.SAdd(ctx, key, members...).Err()
+	return Use("").SAdd(key, members...)
 }
 
 // SMembers 获取集合的所有成员
 func SMembers(key string) ([]string, error) {
-	return redisClient.SMembers(ctx, key).Result()
+	return Use("").SMembers(key)
 }
 
 // SIsMember 检查成员是否在集合中
 func SIsMember(key string, member interface{}) (bool, error) {
-	return redisClient.SIsMember(ctx, key, member).Result()
+	return Use("").SIsMember(key, member)
 }
 
 // SRem 从集合中移除成员
 func SRem(key string, members ...interface{}) error {
-	return redisClient.SRem(ctx, key, members...).Err()
+	return Use("").SRem(key, members...)
 }
 
 // Keys 根据模式查找所有匹配的键
 func Keys(pattern string) ([]string, error) {
-	return redisClient.Keys(ctx, pattern).Result()
+	return Use("").Keys(pattern)
+}
+
+// DeleteByPattern 使用 SCAN 遍历并删除匹配的键，避免 KEYS 阻塞Redis，返回删除数量
+func DeleteByPattern(pattern string) (int64, error) {
+	return Use("").DeleteByPattern(pattern)
 }
 
 // FlushDB 清空当前数据库
 func FlushDB() error {
-	return redisClient.FlushDB(ctx).Err()
+	return Use("").FlushDB()
 }
 
-// Close 关闭Redis连接
+// Close 关闭全部实例的Redis连接
 func Close() error {
-	return redisClient.Close()
+	lock.Lock()
+	defer lock.Unlock()
+	var errList []error
+	for name, client := range clientMap {
+		delete(clientMap, name)
+		if err := client.Close(); nil != err {
+			errList = append(errList, err)
+		}
+	}
+	if 0 < len(errList) {
+		return dError.NewError("关闭Redis连接出错", errList...)
+	}
+	return nil
 }
 
 // Lock 非阻塞锁，获取成功返回 true
 func Lock(key string, value any, expiration time.Duration) (bool, error) {
-	result, err := redisClient.SetNX(ctx, key, value, expiration).Result()
-	if err != nil {
-		return false, err
-	}
-	return result, nil
+	return Use("").Lock(key, value, expiration)
 }
 func Unlock(key string) {
-	_ = redisClient.Del(ctx, key).Err()
+	Use("").Unlock(key)
+}
+
+// SafeUnlock 锁的值与加锁时一致才释放，避免锁过期后被其他实例获取时误删，返回是否释放；
+// keep 大于 0 时不立即删除而是 keep 后过期，用于吸收多实例之间的时钟误差
+func SafeUnlock(key string, value any, keep time.Duration) (bool, error) {
+	return Use("").SafeUnlock(key, value, keep)
 }
 
 // BlockingLock 阻塞锁：在 waitTimeout 内轮询获取锁，获取成功返回 true；超时返回 false, nil
 func BlockingLock(key string, lockExpiration time.Duration) (bool, error) {
-	return BlockingLockWithInterval(key, 1, lockExpiration, 60*time.Second, 50*time.Millisecond)
+	return Use("").BlockingLock(key, lockExpiration)
 }
 
 // BlockingLockWithInterval
-// key 锁键；value 锁的值（建议唯一，解锁时需传入同一 value 调用 Unlock）；
+// key 锁键；value 锁的值（建议唯一，解锁时需传入同一 value 调用 SafeUnlock）；
 // lockExpiration 锁过期时间；waitTimeout 最长等待时间
 // 轮询间隔为 retryInterval，默认 50ms
 func BlockingLockWithInterval(key string, value interface{}, lockExpiration, waitTimeout, retryInterval time.Duration) (bool, error) {
-	deadline := time.Now().Add(waitTimeout)
-	for time.Now().Before(deadline) {
-		ok, err := Lock(key, value, lockExpiration)
-		if err != nil {
-			return false, err
-		}
-		if ok {
-			return true, nil
-		}
-		time.Sleep(retryInterval)
-	}
-	return false, nil
+	return Use("").BlockingLockWithInterval(key, value, lockExpiration, waitTimeout, retryInterval)
 }
@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mini-tiger/fast-api/config"
+	"github.com/mini-tiger/fast-api/core"
+	"gopkg.in/ini.v1"
+)
+
+func init() {
+	config.RegisterRemoteSource("redis", fetchRemote)
+}
+
+// fetchRemote 读取默认实例中的远程配置哈希，字段为 分组:键，如 http:accessLog；
+// key 为哈希键，为空时使用 config:<serverName>:<mode>
+func fetchRemote(fetchCtx context.Context, key string) ([]config.ValueSourceType, error) {
+	client, err := newClient("")
+	if nil != err {
+		return nil, err
+	}
+	if "" == key {
+		coreConfig, err := config.GetCoreConfig()
+		if nil != err {
+			return nil, err
+		}
+		key = fmt.Sprintf("config:%s:%s", coreConfig.ServerName, core.Mode)
+	}
+	fieldMap, err := client.HGetAll(fetchCtx, key).Result()
+	if nil != err {
+		return nil, err
+	}
+	valueList := make([]config.ValueSourceType, 0, len(fieldMap))
+	for field, value := range fieldMap {
+		section, name, ok := strings.Cut(field, ":")
+		if !ok {
+			section, name = ini.DefaultSection, field
+		}
+		valueList = append(valueList, config.ValueSourceType{Section: section, Key: name, Value: value})
+	}
+	return valueList, nil
+}
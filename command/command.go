@@ -0,0 +1,240 @@
+package command
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mini-tiger/fast-api/core"
+	"github.com/mini-tiger/fast-api/dError"
+)
+
+// CommandType 子命令
+type CommandType struct {
+	// Usage 命令说明
+	Usage string
+	// ArgsUsage 位置参数说明，如 <job>
+	ArgsUsage string
+	// Flags 定义命令参数，可为空
+	Flags func(flagSet *flag.FlagSet)
+	// Components 执行前需要初始化的组件，为空时不初始化；执行结束后按顺序退出
+	Components []string
+	// Run 执行命令
+	Run func(c *ContextType) error
+}
+
+// ContextType 单次命令执行的上下文
+type ContextType struct {
+	// Args 解析参数后剩余的位置参数
+	Args    []string
+	FlagSet *flag.FlagSet
+	Out     io.Writer
+	// App 按 Components 初始化的应用，未指定组件时为 nil
+	App *core.AppType
+}
+
+// Arg 获取第 index 个位置参数，不存在时返回空字符串
+func (c *ContextType) Arg(index int) string {
+	if index < len(c.Args) {
+		return c.Args[index]
+	}
+	return ""
+}
+
+// String 获取参数值
+func (c *ContextType) String(name string) string {
+	if f := c.FlagSet.Lookup(name); nil != f {
+		return f.Value.String()
+	}
+	return ""
+}
+
+// Int 获取整数参数值，无法解析时返回 0
+func (c *ContextType) Int(name string) int {
+	value, _ := strconv.Atoi(c.String(name))
+	return value
+}
+
+// Bool 获取布尔参数值
+func (c *ContextType) Bool(name string) bool {
+	return "true" == c.String(name)
+}
+
+// nodeType 命令树节点，既可以是命令也可以只是分组
+type nodeType struct {
+	name     string
+	command  *CommandType
+	children map[string]*nodeType
+}
+
+var lock sync.RWMutex
+var root = &nodeType{children: map[string]*nodeType{}}
+
+func init() {
+	Register("version", &CommandType{
+		Usage: "输出版本、提交、构建时间和Go版本",
+		Run: func(c *ContextType) error {
+			info := core.GetBuildInfo()
+			_, _ = fmt.Fprintf(c.Out, "版本：%s\n提交：%s\n构建时间：%s\nGo版本：%s\n", info.Version, info.GitCommit, info.BuildTime, info.GoVersion)
+			return nil
+		},
+	})
+}
+
+// ErrHelp 输出帮助信息后返回，不视为失败
+var ErrHelp = errors.New("help requested")
+
+// Register 注册命令，path 为空格分隔的命令路径，如 "cron run"；重复注册以后者为准
+func Register(path string, command *CommandType) {
+	lock.Lock()
+	defer lock.Unlock()
+	node := root
+	for _, name := range strings.Fields(path) {
+		child, ok := node.children[name]
+		if !ok {
+			child = &nodeType{name: name, children: map[string]*nodeType{}}
+			node.children[name] = child
+		}
+		node = child
+	}
+	node.command = command
+}
+
+// globalFlags 定义全局参数，全局参数可以写在命令前后
+func globalFlags(flagSet *flag.FlagSet) {
+	flagSet.Func(core.ModeFlag, "运行环境，也可通过环境变量 "+core.ModeEnv+" 指定", func(value string) error {
+		core.SetFlagMode(value)
+		return nil
+	})
+	flagSet.Func("config", "配置文件目录，也可通过环境变量 "+core.ConfigDirEnv+" 指定", func(value string) error {
+		core.ConfigDir = value
+		return nil
+	})
+}
+
+// Execute 执行 os.Args 中的命令，出错时输出错误并以状态码 1 退出
+func Execute() {
+	err := Run(os.Args[1:], os.Stdout)
+	if errors.Is(err, ErrHelp) {
+		return
+	}
+	if nil != err {
+		_, _ = fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
+
+// Run 执行命令，args 不包含程序名
+func Run(args []string, out io.Writer) error {
+	globalSet := flag.NewFlagSet("global", flag.ContinueOnError)
+	globalSet.SetOutput(io.Discard)
+	globalFlags(globalSet)
+	if err := globalSet.Parse(args); nil != err {
+		if errors.Is(err, flag.ErrHelp) {
+			printHelp(out, root, nil)
+			return ErrHelp
+		}
+		return dError.NewError("参数错误", err)
+	}
+	args = globalSet.Args()
+
+	lock.RLock()
+	// 兼容旧用法：第一个参数为环境名称（与命令同名时按命令处理）
+	if 0 < len(args) && core.ModeExist(core.ModeType(args[0])) {
+		if _, ok := root.children[args[0]]; !ok {
+			args = args[1:]
+		}
+	}
+
+	// 按命令路径查找最深的节点
+	node := root
+	var path []string
+	for 0 < len(args) {
+		child, ok := node.children[args[0]]
+		if !ok {
+			break
+		}
+		node = child
+		path = append(path, args[0])
+		args = args[1:]
+	}
+	lock.RUnlock()
+
+	if nil == node.command {
+		if 0 < len(args) && "help" != args[0] {
+			printHelp(out, node, path)
+			return dError.NewError(fmt.Sprintf("未知的命令 %s", strings.Join(append(path, args[0]), " ")))
+		}
+		printHelp(out, node, path)
+		return ErrHelp
+	}
+	return runCommand(node.command, path, args, out)
+}
+
+// runCommand 解析命令参数，按需初始化组件后执行
+func runCommand(command *CommandType, path, args []string, out io.Writer) error {
+	name := strings.Join(path, " ")
+	flagSet := flag.NewFlagSet(name, flag.ContinueOnError)
+	flagSet.SetOutput(out)
+	flagSet.Usage = func() {
+		_, _ = fmt.Fprintf(out, "用法：%s [参数] %s\n%s\n", name, command.ArgsUsage, command.Usage)
+		flagSet.PrintDefaults()
+	}
+	globalFlags(flagSet)
+	if nil != command.Flags {
+		command.Flags(flagSet)
+	}
+	if err := flagSet.Parse(args); nil != err {
+		if errors.Is(err, flag.ErrHelp) {
+			return ErrHelp
+		}
+		return dError.NewError("参数错误", err)
+	}
+
+	c := &ContextType{
+		Args:    flagSet.Args(),
+		FlagSet: flagSet,
+		Out:     out,
+	}
+	if 0 < len(command.Components) {
+		app, err := core.Bootstrap(core.OptionsType{Components: command.Components})
+		if nil != err {
+			return err
+		}
+		c.App = app
+		defer func() {
+			_ = app.Shutdown()
+		}()
+	}
+	return command.Run(c)
+}
+
+// printHelp 输出节点下的全部命令
+func printHelp(out io.Writer, node *nodeType, path []string) {
+	lock.RLock()
+	defer lock.RUnlock()
+	_, _ = fmt.Fprintf(out, "用法：%s [--mode=dev] [--config=dir] <命令> [参数]\n\n可用命令：\n", strings.Join(append([]string{"app"}, path...), " "))
+	var lineList []string
+	var walk func(node *nodeType, prefix string)
+	walk = func(node *nodeType, prefix string) {
+		if nil != node.command {
+			lineList = append(lineList, fmt.Sprintf("  %-24s %s", strings.TrimSpace(prefix+" "+node.command.ArgsUsage), node.command.Usage))
+		}
+		for name, child := range node.children {
+			walk(child, strings.TrimSpace(prefix+" "+name))
+		}
+	}
+	for name, child := range node.children {
+		walk(child, strings.TrimSpace(strings.Join(path, " ")+" "+name))
+	}
+	sort.Strings(lineList)
+	for _, line := range lineList {
+		_, _ = fmt.Fprintln(out, line)
+	}
+}
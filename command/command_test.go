@@ -0,0 +1,57 @@
+package command
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/mini-tiger/fast-api/core"
+)
+
+func TestRun(t *testing.T) {
+	var gotArgs []string
+	var gotPattern string
+	Register("test flush", &CommandType{
+		Usage:     "测试命令",
+		ArgsUsage: "<name>",
+		Flags: func(flagSet *flag.FlagSet) {
+			flagSet.String("pattern", "", "")
+		},
+		Run: func(c *ContextType) error {
+			gotArgs = c.Args
+			gotPattern = c.String("pattern")
+			return nil
+		},
+	})
+	defer core.SetFlagMode("")
+
+	out := &bytes.Buffer{}
+	if err := Run([]string{"--config=/etc/app", "test", "flush", "--mode=test", "--pattern", "user:*", "a"}, out); nil != err {
+		t.Fatal(err)
+	}
+	if "user:*" != gotPattern || 1 != len(gotArgs) || "a" != gotArgs[0] {
+		t.Fatalf("%s %v", gotPattern, gotArgs)
+	}
+	if "/etc/app" != core.ConfigDir {
+		t.Fatalf("全局参数 --config 未生效: %s", core.ConfigDir)
+	}
+	core.ConfigDir = ""
+	if err := core.ResolveMode(); nil != err || core.Test != core.Mode {
+		t.Fatalf("全局参数 --mode 未生效: %s %v", core.Mode, err)
+	}
+
+	// 分组节点输出帮助
+	out.Reset()
+	if err := Run([]string{"test"}, out); !errors.Is(err, ErrHelp) {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "test flush <name>") {
+		t.Fatal(out.String())
+	}
+
+	if err := Run([]string{"test", "unknown"}, out); nil == err || errors.Is(err, ErrHelp) {
+		t.Fatal("未知命令应返回错误")
+	}
+}
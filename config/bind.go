@@ -0,0 +1,351 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mini-tiger/fast-api/dError"
+	"gopkg.in/ini.v1"
+)
+
+// Bind 将分组的配置绑定到结构体指针，全部字段的错误汇总后一起返回
+// 支持的字段类型：string、bool、整数、浮点数、time.Duration 及它们的切片（逗号分隔）
+// 结构体字段绑定子分组，如 [mysql] 中的 Replica 字段绑定 [mysql.replica]；
+// 结构体切片绑定带序号的子分组 [mysql.replicas.0]、[mysql.replicas.1]……；
+// map[string]T 绑定子分组中的全部配置项，ini:"*" 时绑定当前分组的全部配置项；
+// 与 ini 一致，子分组中未配置的键继承父分组的值
+// 支持的标签：
+//
+//	ini:"host"           配置键名，默认为字段名首字母小写，"-" 表示忽略
+//	default:"localhost"  未配置或为空时的默认值
+//	required:"true"      必须配置且不能为空，子分组必须存在
+//	range:"1,65535"      数值或时长的取值范围，两端均包含，可省略一端，如 range:"1s,"
+//
+// 结构体实现 Validate() error 时，字段绑定成功后调用，用于标签无法表达的校验
+func Bind(section string, dest any) error {
+	if err := Load(); nil != err {
+		return err
+	}
+	return bindFile(GetInstance(), section, dest)
+}
+
+// bindFile 从指定配置绑定，热加载时用于校验新配置
+func bindFile(file *ini.File, section string, dest any) error {
+	destValue := reflect.ValueOf(dest)
+	if reflect.Ptr != destValue.Kind() || reflect.Struct != destValue.Elem().Kind() {
+		return dError.NewError(fmt.Sprintf("配置 [%s] 绑定的目标必须是结构体指针", section))
+	}
+	if errList := bindStruct(file, section, destValue.Elem()); 0 < len(errList) {
+		return dError.NewError(fmt.Sprintf("配置 [%s] 错误", section), errList...)
+	}
+	return nil
+}
+
+// bindStruct 绑定结构体的全部字段，返回全部字段的错误
+func bindStruct(file *ini.File, section string, structValue reflect.Value) []error {
+	structType := structValue.Type()
+	var errList []error
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		key := field.Tag.Get("ini")
+		if "-" == key {
+			continue
+		}
+		if "" == key {
+			key = strings.ToLower(field.Name[:1]) + field.Name[1:]
+		}
+		required := "true" == field.Tag.Get("required")
+
+		switch {
+		case reflect.Struct == field.Type.Kind():
+			childSection := section + "." + key
+			if !hasSection(file, childSection) {
+				if required {
+					errList = append(errList, fmt.Errorf("缺少配置分组 [%s]", childSection))
+				}
+				continue
+			}
+			errList = append(errList, bindStruct(file, childSection, structValue.Field(i))...)
+			continue
+		case reflect.Slice == field.Type.Kind() && reflect.Struct == field.Type.Elem().Kind():
+			list := reflect.MakeSlice(field.Type, 0, 0)
+			for index := 0; ; index++ {
+				childSection := fmt.Sprintf("%s.%s.%d", section, key, index)
+				if !hasSection(file, childSection) {
+					break
+				}
+				item := reflect.New(field.Type.Elem()).Elem()
+				errList = append(errList, bindStruct(file, childSection, item)...)
+				list = reflect.Append(list, item)
+			}
+			if required && 0 == list.Len() {
+				errList = append(errList, fmt.Errorf("缺少配置分组 [%s.%s.0]", section, key))
+			}
+			structValue.Field(i).Set(list)
+			continue
+		case reflect.Map == field.Type.Kind() && "*" == key:
+			errList = append(errList, bindMap(file, section, structValue.Field(i), field.Tag.Get("range"))...)
+			continue
+		case reflect.Map == field.Type.Kind():
+			childSection := section + "." + key
+			if required && !hasSection(file, childSection) {
+				errList = append(errList, fmt.Errorf("缺少配置分组 [%s]", childSection))
+				continue
+			}
+			errList = append(errList, bindMap(file, childSection, structValue.Field(i), field.Tag.Get("range"))...)
+			continue
+		}
+
+		value := lookupValue(file, section, key)
+		if "" == value && !hasKey(file, section, key) {
+			envValue, ok, err := lookupEnv(section, key)
+			if nil != err {
+				errList = append(errList, fmt.Errorf("配置 [%s] %s 错误: %w", section, key, err))
+				continue
+			}
+			if ok {
+				value = envValue
+			}
+		}
+		if "" == value {
+			value = field.Tag.Get("default")
+		}
+		if "" == value {
+			if required {
+				errList = append(errList, fmt.Errorf("缺少配置 [%s] %s", section, key))
+			}
+			continue
+		}
+		if err := setField(structValue.Field(i), value, field.Tag.Get("range")); nil != err {
+			errList = append(errList, fmt.Errorf("配置 [%s] %s = %s 错误: %w", section, key, value, err))
+		}
+	}
+	if 0 == len(errList) && structValue.CanAddr() {
+		if validator, ok := structValue.Addr().Interface().(interface{ Validate() error }); ok {
+			if err := validator.Validate(); nil != err {
+				errList = append(errList, err)
+			}
+		}
+	}
+	return errList
+}
+
+// bindMap 将分组中的全部配置项绑定到 map[string]T
+func bindMap(file *ini.File, section string, field reflect.Value, valueRange string) []error {
+	if reflect.String != field.Type().Key().Kind() {
+		return []error{fmt.Errorf("配置 [%s] 不支持的字段类型 %s", section, field.Type())}
+	}
+	mapValue := reflect.MakeMap(field.Type())
+	var errList []error
+	if iniSection, err := file.GetSection(section); nil == err {
+		for _, key := range iniSection.Keys() {
+			item := reflect.New(field.Type().Elem()).Elem()
+			if err := setField(item, key.Value(), valueRange); nil != err {
+				errList = append(errList, fmt.Errorf("配置 [%s] %s = %s 错误: %w", section, key.Name(), key.Value(), err))
+				continue
+			}
+			mapValue.SetMapIndex(reflect.ValueOf(key.Name()), item)
+		}
+	}
+	field.Set(mapValue)
+	return errList
+}
+
+// hasKey 分组中是否配置了该键，不查找父分组
+func hasKey(file *ini.File, section, key string) bool {
+	iniSection, err := file.GetSection(section)
+	if nil != err {
+		return false
+	}
+	// HasKey 会查找父分组，这里只看当前分组
+	for _, name := range iniSection.KeyStrings() {
+		if key == name {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSection 分组是否存在，不会创建分组
+func hasSection(file *ini.File, section string) bool {
+	_, err := file.GetSection(section)
+	return nil == err
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setField 解析字符串并写入字段，同时校验取值范围
+func setField(field reflect.Value, value, valueRange string) error {
+	if durationType == field.Type() {
+		duration, err := time.ParseDuration(value)
+		if nil != err {
+			return err
+		}
+		if err := checkRange(float64(duration), valueRange, func(s string) (float64, error) {
+			d, err := time.ParseDuration(s)
+			return float64(d), err
+		}); nil != err {
+			return err
+		}
+		field.SetInt(int64(duration))
+		return nil
+	}
+
+	parseNumber := func(s string) (float64, error) {
+		return strconv.ParseFloat(s, 64)
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		boolValue, err := strconv.ParseBool(value)
+		if nil != err {
+			return err
+		}
+		field.SetBool(boolValue)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		intValue, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if nil != err {
+			return err
+		}
+		if err := checkRange(float64(intValue), valueRange, parseNumber); nil != err {
+			return err
+		}
+		field.SetInt(intValue)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		uintValue, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if nil != err {
+			return err
+		}
+		if err := checkRange(float64(uintValue), valueRange, parseNumber); nil != err {
+			return err
+		}
+		field.SetUint(uintValue)
+	case reflect.Float32, reflect.Float64:
+		floatValue, err := strconv.ParseFloat(value, field.Type().Bits())
+		if nil != err {
+			return err
+		}
+		if err := checkRange(floatValue, valueRange, parseNumber); nil != err {
+			return err
+		}
+		field.SetFloat(floatValue)
+	case reflect.Slice:
+		list := reflect.MakeSlice(field.Type(), 0, 0)
+		for _, itemValue := range strings.Split(value, ",") {
+			if itemValue = strings.TrimSpace(itemValue); "" == itemValue {
+				continue
+			}
+			item := reflect.New(field.Type().Elem()).Elem()
+			if reflect.Slice == item.Kind() {
+				return fmt.Errorf("不支持的字段类型 %s", field.Type())
+			}
+			if err := setField(item, itemValue, valueRange); nil != err {
+				return err
+			}
+			list = reflect.Append(list, item)
+		}
+		field.Set(list)
+	default:
+		return fmt.Errorf("不支持的字段类型 %s", field.Type())
+	}
+	return nil
+}
+
+// checkRange 校验取值范围，valueRange 格式为 "min,max"
+func checkRange(value float64, valueRange string, parse func(string) (float64, error)) error {
+	if "" == valueRange {
+		return nil
+	}
+	minStr, maxStr, _ := strings.Cut(valueRange, ",")
+	if minStr = strings.TrimSpace(minStr); "" != minStr {
+		minValue, err := parse(minStr)
+		if nil != err {
+			return fmt.Errorf("取值范围 %s 格式错误", valueRange)
+		}
+		if value < minValue {
+			return fmt.Errorf("不能小于 %s", minStr)
+		}
+	}
+	if maxStr = strings.TrimSpace(maxStr); "" != maxStr {
+		maxValue, err := parse(maxStr)
+		if nil != err {
+			return fmt.Errorf("取值范围 %s 格式错误", valueRange)
+		}
+		if value > maxValue {
+			return fmt.Errorf("不能大于 %s", maxStr)
+		}
+	}
+	return nil
+}
+
+// bindingType 启动时需要校验的配置绑定
+type bindingType struct {
+	section string
+	dest    any
+}
+
+var bindingLock sync.Mutex
+var bindingList []bindingType
+
+// Register 登记需要校验的配置分组，启动时与内置组件的配置一起校验并绑定到 dest；
+// 热加载时新配置同样需要通过校验，但不会修改 dest，需要最新值时在 OnChange 中重新 Bind
+func Register(section string, dest any) {
+	bindingLock.Lock()
+	defer bindingLock.Unlock()
+	bindingList = append(bindingList, bindingType{section: section, dest: dest})
+}
+
+// Validate 读取配置并绑定 [core] 及全部已登记的分组，所有分组的错误汇总到一个 dError
+func Validate() error {
+	if err := Load(); nil != err {
+		return err
+	}
+	return validateFile(GetInstance(), false)
+}
+
+// validateFile 校验 [core] 及全部已登记的分组；dryRun 时绑定到新建的同类型结构体，不修改登记的 dest
+func validateFile(file *ini.File, dryRun bool) error {
+	bindingLock.Lock()
+	list := append([]bindingType{}, bindingList...)
+	bindingLock.Unlock()
+
+	var errList []error
+	if err := bindFile(file, "core", &CoreConfigType{}); nil != err {
+		errList = append(errList, err)
+	}
+	for _, binding := range list {
+		dest := binding.dest
+		if dryRun && reflect.Ptr == reflect.TypeOf(dest).Kind() {
+			dest = reflect.New(reflect.TypeOf(dest).Elem()).Interface()
+		}
+		if err := bindFile(file, binding.section, dest); nil != err {
+			errList = append(errList, err)
+		}
+	}
+	if 0 < len(errList) {
+		return dError.NewError("配置校验失败", errList...)
+	}
+	return nil
+}
+
+// CoreConfigType [core] 配置
+type CoreConfigType struct {
+	// ServerName 服务名称，用于日志来源和 OSS 路径
+	ServerName string `ini:"serverName" required:"true"`
+	// Timezone 全局时区，默认 Asia/Shanghai
+	Timezone string `ini:"timezone"`
+}
+
+// GetCoreConfig 读取并校验 [core] 配置
+func GetCoreConfig() (*CoreConfigType, error) {
+	coreConfig := &CoreConfigType{}
+	return coreConfig, Bind("core", coreConfig)
+}
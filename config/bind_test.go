@@ -0,0 +1,52 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type testBindType struct {
+	Host    string        `ini:"host" required:"true"`
+	Port    int           `ini:"port" default:"3306" range:"1,65535"`
+	Timeout time.Duration `ini:"timeout" default:"10s" range:"1s,1m"`
+	Debug   bool          `ini:"debug"`
+	Tags    []string      `ini:"tags"`
+	MaxOpen int
+	ignored string
+}
+
+func TestBind(t *testing.T) {
+	useConfigDir(t, map[string]string{
+		"dev.ini": "[core]\nserverName = demo\n[ok]\nhost = db\ntimeout = 30s\ndebug = true\ntags = a, b\nmaxOpen = 5\n[bad]\nport = 70000\ntimeout = 1h\n[worse]\nhost = db\nport = abc\n",
+	})
+
+	okConfig := &testBindType{}
+	if err := Bind("ok", okConfig); nil != err {
+		t.Fatal(err)
+	}
+	if "db" != okConfig.Host || 3306 != okConfig.Port || 30*time.Second != okConfig.Timeout ||
+		!okConfig.Debug || 2 != len(okConfig.Tags) || "b" != okConfig.Tags[1] || 5 != okConfig.MaxOpen {
+		t.Fatalf("%+v", okConfig)
+	}
+
+	// 一个分组的全部字段错误一起返回
+	err := Bind("bad", &testBindType{})
+	if nil == err {
+		t.Fatal("应返回错误")
+	}
+	for _, keyword := range []string{"缺少配置 [bad] host", "[bad] port", "[bad] timeout"} {
+		if !strings.Contains(err.Error(), keyword) {
+			t.Errorf("错误中缺少 %s: %v", keyword, err)
+		}
+	}
+
+	// 多个分组的错误汇总
+	Register("bad", &testBindType{})
+	Register("worse", &testBindType{})
+	defer func() { bindingList = nil }()
+	err = Validate()
+	if nil == err || !strings.Contains(err.Error(), "[bad]") || !strings.Contains(err.Error(), "[worse] port") {
+		t.Fatalf("应汇总全部分组的错误: %v", err)
+	}
+}
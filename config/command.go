@@ -0,0 +1,68 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mini-tiger/fast-api/command"
+	"github.com/mini-tiger/fast-api/core"
+	"github.com/mini-tiger/fast-api/dError"
+)
+
+func init() {
+	command.Register("config dump", &command.CommandType{
+		Usage:      "输出当前运行环境生效的配置，敏感配置默认隐藏",
+		Components: []string{core.ComponentConfig},
+		Flags: func(flagSet *flag.FlagSet) {
+			flagSet.Bool("source", false, "同时输出每个值来自哪一层")
+			flagSet.Bool("show-secrets", false, "输出敏感配置的原值")
+		},
+		Run: func(c *command.ContextType) error {
+			redact := !c.Bool("show-secrets")
+			if c.Bool("source") {
+				for _, value := range Explain() {
+					if redact {
+						value.Value = Redact(value.Key, value.Value)
+					}
+					_, _ = fmt.Fprintf(c.Out, "[%s] %s = %s    # %s\n", value.Section, value.Key, value.Value, value.Layer)
+				}
+				return nil
+			}
+			return Dump(c.Out, redact)
+		},
+	})
+	command.Register("config encrypt", &command.CommandType{
+		Usage:     "使用环境变量 " + MasterKeyEnv + " 中的主密钥加密配置值，未指定值时从标准输入读取",
+		ArgsUsage: "[value]",
+		Run: func(c *command.ContextType) error {
+			value := c.Arg(0)
+			if "" == value {
+				data, err := io.ReadAll(os.Stdin)
+				if nil != err {
+					return dError.NewError("读取标准输入出错", err)
+				}
+				value = strings.TrimRight(string(data), "\r\n")
+			}
+			encrypted, err := Encrypt(value)
+			if nil != err {
+				return dError.NewError("加密失败", err)
+			}
+			_, _ = fmt.Fprintln(c.Out, encrypted)
+			return nil
+		},
+	})
+	command.Register("config keygen", &command.CommandType{
+		Usage: "生成随机主密钥，设置到环境变量 " + MasterKeyEnv,
+		Run: func(c *command.ContextType) error {
+			key, err := GenerateKey()
+			if nil != err {
+				return err
+			}
+			_, _ = fmt.Fprintln(c.Out, key)
+			return nil
+		},
+	})
+}
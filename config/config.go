@@ -1,7 +1,9 @@
 package config
 
 import (
-	"fmt"
+	"context"
+	"sync"
+	"time"
 
 	"github.com/mini-tiger/fast-api/core"
 	"github.com/mini-tiger/fast-api/dError"
@@ -9,16 +11,102 @@ import (
 )
 
 var instance *ini.File
+var lock sync.Mutex
+
+// sourceMap 每个配置值来自哪一层
+var sourceMap = map[string]string{}
 
 func init() {
-	configPath := fmt.Sprintf("%s/conf/%s.ini", core.AppPath, core.Mode)
-	var err error
-	instance, err = ini.Load(configPath)
+	core.RegisterComponent(&core.ComponentType{
+		Name:     core.ComponentConfig,
+		Order:    10,
+		Validate: Validate,
+		Init:     Load,
+		Start:    start,
+		Stop:     stop,
+	})
+}
+
+// Load 读取并合并各层配置，已读取成功时直接返回
+// 合并顺序：conf/base < conf/<mode> < conf/local < 远程配置（[config] remote） < FASTAPI_ 开头的环境变量
+func Load() error {
+	lock.Lock()
+	defer lock.Unlock()
+	if nil != instance {
+		return nil
+	}
+	// 自定义环境在 core 初始化之后才注册，读取配置前重新解析
+	if err := core.ResolveMode(); nil != err {
+		return err
+	}
+	file, fileSourceMap, err := loadLayers()
 	if err != nil {
-		panic(dError.NewError("读取配置文件出错", err))
+		return err
 	}
+	// 全局时区，未配置时保持 core 中的默认时区
+	if timezone := lookupValue(file, "core", "timezone"); "" != timezone {
+		if err := core.SetTimezone(timezone); nil != err {
+			return dError.NewError("配置 [core] timezone 错误", err)
+		}
+	}
+	instance = file
+	sourceMap = fileSourceMap
+	return nil
 }
 
+// lookupValue 读取配置值，不存在时返回空字符串且不会创建分组和键
+func lookupValue(file *ini.File, section, key string) string {
+	iniSection, err := file.GetSection(section)
+	if nil != err || !iniSection.HasKey(key) {
+		return ""
+	}
+	return iniSection.Key(key).Value()
+}
+
+// GetInstance 获取合并后的配置，未读取时自动读取，读取失败会 panic
 func GetInstance() *ini.File {
+	if err := Load(); nil != err {
+		panic(err)
+	}
+	// 热加载会整体替换 instance
+	lock.Lock()
+	defer lock.Unlock()
 	return instance
 }
+
+// ConfigType [config] 配置
+type ConfigType struct {
+	// WatchInterval 检查配置文件修改的间隔，为 0 时不自动热加载
+	WatchInterval time.Duration `ini:"watchInterval" range:"0s,"`
+	// Remote 远程配置来源，如 mysql（config_kv 表）、redis（哈希），为空时不使用
+	Remote string `ini:"remote"`
+	// RemoteInterval 刷新远程配置的间隔
+	RemoteInterval time.Duration `ini:"remoteInterval" default:"30s" range:"1s,"`
+	// RemoteKey 远程配置在来源中的位置，如 redis 的哈希键，为空时由来源决定
+	RemoteKey string `ini:"remoteKey"`
+}
+
+// GetConfig 读取并校验 [config] 配置
+func GetConfig() (*ConfigType, error) {
+	selfConfig := &ConfigType{}
+	return selfConfig, Bind("config", selfConfig)
+}
+
+// start 应用启动后开启热加载和远程配置刷新，此时远程配置依赖的组件已初始化
+func start() error {
+	selfConfig, err := GetConfig()
+	if nil != err {
+		return err
+	}
+	startWatch(selfConfig.WatchInterval)
+	startRemote(selfConfig)
+	warnUnusedEnv()
+	return nil
+}
+
+// stop 应用退出时停止热加载和远程配置刷新
+func stop(ctx context.Context) error {
+	stopWatching()
+	stopRemote()
+	return nil
+}
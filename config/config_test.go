@@ -0,0 +1,95 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mini-tiger/fast-api/core"
+)
+
+// useConfigDir 使用临时目录作为配置目录并写入配置文件，测试结束后恢复
+func useConfigDir(t *testing.T, fileMap map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range fileMap {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0666); nil != err {
+			t.Fatal(err)
+		}
+	}
+	core.ConfigDir = dir
+	instance = nil
+	t.Cleanup(func() {
+		core.ConfigDir = ""
+		instance = nil
+	})
+	return dir
+}
+
+func TestLayer(t *testing.T) {
+	useConfigDir(t, map[string]string{
+		"base.ini":  "[mysql]\nhost = base\nport = 3306\nusername = root\n[redis]\nhost = redis-base\n",
+		"dev.ini":   "[mysql]\nhost = dev\n",
+		"local.ini": "[mysql]\nusername = me\n",
+	})
+	t.Setenv("FASTAPI_MYSQL_PORT", "3307")
+
+	if err := Load(); nil != err {
+		t.Fatal(err)
+	}
+	mysqlConfig := GetInstance().Section("mysql")
+	if "dev" != mysqlConfig.Key("host").Value() || "3307" != mysqlConfig.Key("port").Value() || "me" != mysqlConfig.Key("username").Value() {
+		t.Fatalf("合并结果错误: %v", mysqlConfig.KeysHash())
+	}
+	if "redis-base" != GetInstance().Section("redis").Key("host").Value() {
+		t.Fatal("base.ini 中的配置应保留")
+	}
+
+	caseList := [][3]string{
+		{"mysql", "host", "dev.ini"},
+		{"mysql", "port", "env FASTAPI_MYSQL_PORT"},
+		{"mysql", "username", "local.ini"},
+		{"redis", "host", "base.ini"},
+	}
+	for _, c := range caseList {
+		if c[2] != SourceOf(c[0], c[1]) {
+			t.Errorf("[%s] %s 来源期望 %s 实际 %s", c[0], c[1], c[2], SourceOf(c[0], c[1]))
+		}
+	}
+	if 4 != len(Explain()) {
+		t.Fatalf("%+v", Explain())
+	}
+}
+
+func TestLayerMissingModeFile(t *testing.T) {
+	useConfigDir(t, map[string]string{
+		"base.ini": "[mysql]\nhost = base\n",
+	})
+	if err := Load(); nil == err {
+		t.Fatal("缺少当前环境的配置文件时应返回错误")
+	}
+}
+
+func TestEnvOverrideDefault(t *testing.T) {
+	useConfigDir(t, map[string]string{
+		"dev.ini": "[core]\nserverName = demo\n[mysql]\nhost = dev\n",
+	})
+	t.Setenv("FASTAPI_MYSQL_PASSWORD", "secret")
+	t.Setenv("FASTAPI_MYSQL_PORT", "3307")
+	t.Setenv("FASTAPI_MYSQL_TYPO", "x")
+
+	dest := &struct {
+		Host     string `ini:"host"`
+		Port     int    `ini:"port" default:"3306"`
+		Password string `ini:"password"`
+	}{}
+	if err := Bind("mysql", dest); nil != err {
+		t.Fatal(err)
+	}
+	if "dev" != dest.Host || 3307 != dest.Port || "secret" != dest.Password {
+		t.Fatalf("文件中不存在的键应从环境变量读取: %+v", dest)
+	}
+	unusedList := unusedEnvNames()
+	if 1 != len(unusedList) || "FASTAPI_MYSQL_TYPO" != unusedList[0] {
+		t.Fatal("应提示未匹配的环境变量", unusedList)
+	}
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"io"
+	"strings"
+	"sync"
+
+	"gopkg.in/ini.v1"
+)
+
+// RedactedValue 敏感配置输出时的替代值
+const RedactedValue = "******"
+
+var secretLock sync.Mutex
+
+// secretPatternList 键名包含其中任一项（不区分大小写）即视为敏感配置
+var secretPatternList = []string{"password", "secret", "key", "token", "credential"}
+
+// RegisterSecretPattern 添加敏感键名的匹配规则，如 "dsn"
+func RegisterSecretPattern(pattern string) {
+	secretLock.Lock()
+	defer secretLock.Unlock()
+	secretPatternList = append(secretPatternList, strings.ToLower(pattern))
+}
+
+// IsSecret 键名是否为敏感配置
+func IsSecret(key string) bool {
+	secretLock.Lock()
+	defer secretLock.Unlock()
+	key = strings.ToLower(key)
+	for _, pattern := range secretPatternList {
+		if strings.Contains(key, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact 敏感配置的非空值替换为 RedactedValue
+func Redact(key, value string) string {
+	if "" != value && IsSecret(key) {
+		return RedactedValue
+	}
+	return value
+}
+
+// Dump 以 ini 格式输出当前生效的配置（已合并各层、环境变量并解析引用和加密值），redact 时隐藏敏感配置
+func Dump(w io.Writer, redact bool) error {
+	if err := Load(); nil != err {
+		return err
+	}
+	file := GetInstance()
+	dumpFile := ini.Empty()
+	for _, section := range file.Sections() {
+		dumpSection := dumpFile.Section(section.Name())
+		for _, key := range section.Keys() {
+			value := key.Value()
+			if redact {
+				value = Redact(key.Name(), value)
+			}
+			_, _ = dumpSection.NewKey(key.Name(), value)
+		}
+	}
+	_, err := dumpFile.WriteTo(w)
+	return err
+}
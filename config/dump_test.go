@@ -0,0 +1,33 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+	useConfigDir(t, map[string]string{
+		"dev.ini": "[core]\nserverName = demo\n[mysql]\nhost = db\npassword = ${env:TEST_DUMP_PASSWORD}\n[oss]\naccessKeySecret = abc\nemptyToken =\n",
+	})
+	t.Setenv("TEST_DUMP_PASSWORD", "p@ss")
+
+	var builder strings.Builder
+	if err := Dump(&builder, true); nil != err {
+		t.Fatal(err)
+	}
+	output := builder.String()
+	if strings.Contains(output, "p@ss") || strings.Contains(output, "abc") || !strings.Contains(output, "db") {
+		t.Fatal(output)
+	}
+	if 2 != strings.Count(output, RedactedValue) {
+		t.Fatalf("空值不需要隐藏: %s", output)
+	}
+
+	builder.Reset()
+	if err := Dump(&builder, false); nil != err {
+		t.Fatal(err)
+	}
+	if !strings.Contains(builder.String(), "p@ss") {
+		t.Fatal("不隐藏时应输出解析后的值")
+	}
+}
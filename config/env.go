@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// envLock 保护 envUsedMap
+var envLock sync.Mutex
+
+// envUsedMap 已匹配到配置项的 FASTAPI_ 环境变量，用于提示拼写错误等未生效的环境变量
+var envUsedMap = map[string]bool{}
+
+// markEnvUsed 记录已匹配到配置项的环境变量
+func markEnvUsed(envName string) {
+	envLock.Lock()
+	defer envLock.Unlock()
+	envUsedMap[envName] = true
+}
+
+// lookupEnv 配置文件中不存在的键从环境变量读取，使绑定的默认值同样可以被环境变量覆盖；
+// 与文件中的值一样支持 ${env:}、${file:} 引用和 ENC() 加密值
+func lookupEnv(section, key string) (string, bool, error) {
+	envName := EnvName(section, key)
+	value, ok := os.LookupEnv(envName)
+	if !ok {
+		return "", false, nil
+	}
+	markEnvUsed(envName)
+	value, err := ResolveValue(value)
+	if nil != err {
+		return "", true, fmt.Errorf("环境变量 %s 错误: %w", envName, err)
+	}
+	return value, true, nil
+}
+
+// unusedEnvNames 未匹配到任何配置项的 FASTAPI_ 环境变量
+func unusedEnvNames() []string {
+	envLock.Lock()
+	defer envLock.Unlock()
+	var nameList []string
+	for _, env := range os.Environ() {
+		name, _, _ := strings.Cut(env, "=")
+		if strings.HasPrefix(name, EnvPrefix) && !envUsedMap[name] {
+			nameList = append(nameList, name)
+		}
+	}
+	sort.Strings(nameList)
+	return nameList
+}
+
+// warnUnusedEnv 提示未生效的 FASTAPI_ 环境变量，应用启动后调用，此时各组件的配置均已绑定
+func warnUnusedEnv() {
+	for _, name := range unusedEnvNames() {
+		_, _ = fmt.Fprintf(os.Stderr, "环境变量 %s 没有对应的配置项，未生效\n", name)
+	}
+}
@@ -0,0 +1,214 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/ini.v1"
+	"gopkg.in/yaml.v3"
+)
+
+// ParseFuncType 将配置文件内容解析为嵌套结构，键为分组或配置名，值为标量、列表或下一层结构
+type ParseFuncType func(data []byte) (map[string]any, error)
+
+// formatType 按扩展名选择的配置格式
+type formatType struct {
+	ext   string
+	parse ParseFuncType
+}
+
+var formatLock sync.Mutex
+
+// formatList 同一层存在多个格式的文件时报错，按此顺序查找
+var formatList = []formatType{
+	{ext: ".ini", parse: parseIni},
+	{ext: ".yaml", parse: parseYaml},
+	{ext: ".yml", parse: parseYaml},
+	{ext: ".toml", parse: parseToml},
+	{ext: ".json", parse: parseJson},
+}
+
+// RegisterFormat 注册配置格式，ext 为带 . 的扩展名，已存在时替换
+func RegisterFormat(ext string, parse ParseFuncType) {
+	formatLock.Lock()
+	defer formatLock.Unlock()
+	for i := range formatList {
+		if ext == formatList[i].ext {
+			formatList[i].parse = parse
+			return
+		}
+	}
+	formatList = append(formatList, formatType{ext: ext, parse: parse})
+}
+
+func formats() []formatType {
+	formatLock.Lock()
+	defer formatLock.Unlock()
+	return append([]formatType{}, formatList...)
+}
+
+// parseIni 分组名按 . 拆分为嵌套结构，如 [mysql.orders] 对应 mysql.orders
+func parseIni(data []byte) (map[string]any, error) {
+	file, err := ini.Load(data)
+	if nil != err {
+		return nil, err
+	}
+	tree := map[string]any{}
+	for _, section := range file.Sections() {
+		node := tree
+		if ini.DefaultSection != section.Name() {
+			for _, name := range strings.Split(section.Name(), ".") {
+				child, ok := node[name].(map[string]any)
+				if !ok {
+					child = map[string]any{}
+					node[name] = child
+				}
+				node = child
+			}
+		}
+		for _, key := range section.Keys() {
+			node[key.Name()] = key.Value()
+		}
+	}
+	return tree, nil
+}
+
+func parseYaml(data []byte) (map[string]any, error) {
+	tree := map[string]any{}
+	if err := yaml.Unmarshal(data, &tree); nil != err {
+		return nil, err
+	}
+	return normalize(tree).(map[string]any), nil
+}
+
+func parseToml(data []byte) (map[string]any, error) {
+	tree := map[string]any{}
+	if err := toml.Unmarshal(data, &tree); nil != err {
+		return nil, err
+	}
+	return normalize(tree).(map[string]any), nil
+}
+
+func parseJson(data []byte) (map[string]any, error) {
+	tree := map[string]any{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&tree); nil != err {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// normalize 统一各格式解析出的类型，如 toml 的表数组、yaml 的非字符串键
+func normalize(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			v[key] = normalize(item)
+		}
+		return v
+	case map[any]any:
+		tree := map[string]any{}
+		for key, item := range v {
+			tree[fmt.Sprint(key)] = normalize(item)
+		}
+		return tree
+	case []map[string]any:
+		list := make([]any, 0, len(v))
+		for _, item := range v {
+			list = append(list, normalize(item))
+		}
+		return list
+	case []any:
+		for i, item := range v {
+			v[i] = normalize(item)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// mergeTree 将 src 合并到 dst，结构逐层合并，标量和列表整体覆盖
+func mergeTree(dst, src map[string]any) {
+	for key, value := range src {
+		srcChild, srcOk := value.(map[string]any)
+		dstChild, dstOk := dst[key].(map[string]any)
+		if srcOk && dstOk {
+			mergeTree(dstChild, srcChild)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+// flattenTree 将嵌套结构展开为分组和配置项：
+// 结构展开为以 . 连接的分组，如 mysql.orders；结构列表展开为带序号的分组，如 mysql.replicas.0；
+// 标量列表以逗号连接；根上的标量属于默认分组
+func flattenTree(tree map[string]any, fn func(section, key, value string)) {
+	flattenNode(ini.DefaultSection, tree, fn)
+}
+
+func flattenNode(section string, node map[string]any, fn func(section, key, value string)) {
+	keyList := make([]string, 0, len(node))
+	for key := range node {
+		keyList = append(keyList, key)
+	}
+	sort.Strings(keyList)
+	for _, key := range keyList {
+		switch v := node[key].(type) {
+		case map[string]any:
+			flattenNode(childSection(section, key), v, fn)
+		case []any:
+			if 0 < len(v) {
+				if _, ok := v[0].(map[string]any); ok {
+					for i, item := range v {
+						if child, ok := item.(map[string]any); ok {
+							flattenNode(childSection(section, key, strconv.Itoa(i)), child, fn)
+						}
+					}
+					continue
+				}
+			}
+			itemList := make([]string, 0, len(v))
+			for _, item := range v {
+				itemList = append(itemList, scalarString(item))
+			}
+			fn(section, key, strings.Join(itemList, ","))
+		default:
+			fn(section, key, scalarString(v))
+		}
+	}
+}
+
+func childSection(section string, nameList ...string) string {
+	if ini.DefaultSection == section {
+		return strings.Join(nameList, ".")
+	}
+	return section + "." + strings.Join(nameList, ".")
+}
+
+// scalarString 标量转为字符串，与 ini 中的写法一致
+func scalarString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+type testReplicaType struct {
+	Host string `ini:"host" required:"true"`
+	Port int    `ini:"port" default:"3306"`
+}
+
+type testFormatType struct {
+	Host     string            `ini:"host"`
+	Tags     []string          `ini:"tags"`
+	PortList []int             `ini:"portList"`
+	Primary  testReplicaType   `ini:"primary" required:"true"`
+	Replicas []testReplicaType `ini:"replicas"`
+	Jobs     map[string]string `ini:"jobs"`
+}
+
+func TestFormat(t *testing.T) {
+	useConfigDir(t, map[string]string{
+		"base.yaml": `
+core:
+  serverName: demo
+mysql:
+  host: base
+  tags: [a, b]
+  portList: [1, 2]
+  primary:
+    host: p
+  replicas:
+    - host: r0
+    - host: r1
+      port: 3307
+  jobs:
+    cleanup: "0 * * * *"
+`,
+		"dev.toml": `
+[mysql]
+host = "dev"
+
+[mysql.jobs]
+report = "0 0 * * *"
+`,
+		"local.json": `{"mysql": {"replicas": [{"host": "local"}]}}`,
+	})
+
+	formatConfig := &testFormatType{}
+	if err := Bind("mysql", formatConfig); nil != err {
+		t.Fatal(err)
+	}
+	if "dev" != formatConfig.Host || 2 != len(formatConfig.Tags) || 2 != formatConfig.PortList[1] || "p" != formatConfig.Primary.Host {
+		t.Fatalf("%+v", formatConfig)
+	}
+	// 列表整体覆盖，结构逐层合并
+	if 1 != len(formatConfig.Replicas) || "local" != formatConfig.Replicas[0].Host || 3306 != formatConfig.Replicas[0].Port {
+		t.Fatalf("%+v", formatConfig.Replicas)
+	}
+	if 2 != len(formatConfig.Jobs) || "0 0 * * *" != formatConfig.Jobs["report"] {
+		t.Fatalf("%+v", formatConfig.Jobs)
+	}
+
+	// 通过 *ini.File 读取展开后的分组
+	if "r0" == GetInstance().Section("mysql.replicas.0").Key("host").Value() {
+		t.Fatal("列表应被 local.json 覆盖")
+	}
+	if "base.yaml" != SourceOf("mysql.primary", "host") || "dev.toml" != SourceOf("mysql", "host") || "local.json" != SourceOf("mysql.replicas.0", "host") {
+		t.Fatalf("%+v", Explain())
+	}
+}
+
+func TestFormatConflict(t *testing.T) {
+	useConfigDir(t, map[string]string{
+		"dev.ini":  "[core]\nserverName = demo\n",
+		"dev.yaml": "core:\n  serverName: demo\n",
+	})
+	if err := Load(); nil == err || !strings.Contains(err.Error(), "只能保留一个") {
+		t.Fatalf("同一层存在多个格式时应返回错误: %v", err)
+	}
+}
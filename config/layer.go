@@ -0,0 +1,178 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mini-tiger/fast-api/core"
+	"github.com/mini-tiger/fast-api/dError"
+	"gopkg.in/ini.v1"
+)
+
+// EnvPrefix 环境变量覆盖配置的前缀，如 FASTAPI_MYSQL_HOST 覆盖 [mysql] host，
+// 分组名中的 . 替换为 _；配置文件中不存在的键在绑定时读取，同样可以覆盖默认值
+const EnvPrefix = "FASTAPI_"
+
+// layerType 配置层，后加载的层覆盖先加载的层
+type layerType struct {
+	// name 不含扩展名的文件名，如 base、dev
+	name     string
+	optional bool
+}
+
+// candidates 该层可能的配置文件，每种格式一个
+func (l layerType) candidates() []string {
+	var pathList []string
+	for _, format := range formats() {
+		pathList = append(pathList, core.ConfigPath(l.name+format.ext))
+	}
+	return pathList
+}
+
+// resolve 查找该层的配置文件，按扩展名选择格式；不存在时 path 为空，存在多个格式时返回错误
+func (l layerType) resolve() (string, ParseFuncType, error) {
+	var path string
+	var parse ParseFuncType
+	for _, format := range formats() {
+		formatPath := core.ConfigPath(l.name + format.ext)
+		if !core.FileExist(formatPath) {
+			continue
+		}
+		if "" != path {
+			return "", nil, fmt.Errorf("配置文件 %s 和 %s 只能保留一个", filepath.Base(path), filepath.Base(formatPath))
+		}
+		path = formatPath
+		parse = format.parse
+	}
+	if "" == path && !l.optional {
+		return "", nil, fmt.Errorf("缺少配置文件 %s", core.ConfigPath(l.name+".ini"))
+	}
+	return path, parse, nil
+}
+
+// ValueSourceType 配置值及其来源
+type ValueSourceType struct {
+	Section string `json:"section"`
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	// Layer 生效的配置层，如 base.ini、env FASTAPI_MYSQL_HOST
+	Layer string `json:"layer"`
+}
+
+// layerList 配置层：conf/base（可选） < conf/<mode> < conf/local（可选），
+// 扩展名可以是 .ini、.yaml、.yml、.toml、.json 或 RegisterFormat 注册的格式
+func layerList() []layerType {
+	return []layerType{
+		{name: "base", optional: true},
+		{name: string(core.Mode)},
+		{name: "local", optional: true},
+	}
+}
+
+// loadLayers 依次读取配置层并合并，覆盖远程配置后再用环境变量覆盖，最后解析引用和加密值，返回合并后的配置和每个值的来源
+// 各格式先解析为嵌套结构逐层合并，再展开为 ini 分组，已有的 *ini.File 用法不受格式影响
+func loadLayers() (*ini.File, map[string]string, error) {
+	tree := map[string]any{}
+	sourceMap := map[string]string{}
+
+	var errList []error
+	for _, layer := range layerList() {
+		path, parse, err := layer.resolve()
+		if nil != err {
+			errList = append(errList, err)
+			continue
+		}
+		if "" == path {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if nil != err {
+			errList = append(errList, fmt.Errorf("读取 %s 出错: %w", path, err))
+			continue
+		}
+		layerTree, err := parse(data)
+		if nil != err {
+			errList = append(errList, fmt.Errorf("读取 %s 出错: %w", path, err))
+			continue
+		}
+		name := filepath.Base(path)
+		flattenTree(layerTree, func(section, key, value string) {
+			sourceMap[sourceKey(section, key)] = name
+		})
+		mergeTree(tree, layerTree)
+	}
+	if 0 < len(errList) {
+		return nil, nil, dError.NewError("读取配置文件出错", errList...)
+	}
+
+	merged := ini.Empty()
+	flattenTree(tree, func(section, key, value string) {
+		// Key() 会查找父分组中的同名键，如 [mysql.orders] 找到 [mysql] 的键，这里必须写入当前分组
+		_, _ = merged.Section(section).NewKey(key, value)
+	})
+	applyRemote(merged, sourceMap)
+	for _, section := range merged.Sections() {
+		for _, key := range section.Keys() {
+			envName := EnvName(section.Name(), key.Name())
+			if value, ok := os.LookupEnv(envName); ok {
+				key.SetValue(value)
+				sourceMap[sourceKey(section.Name(), key.Name())] = "env " + envName
+				markEnvUsed(envName)
+			}
+		}
+	}
+	if err := resolveFile(merged, sourceMap); nil != err {
+		return nil, nil, err
+	}
+	return merged, sourceMap, nil
+}
+
+// EnvName 配置项对应的环境变量名，如 [mysql] host 对应 FASTAPI_MYSQL_HOST
+func EnvName(section, key string) string {
+	name := key
+	if ini.DefaultSection != section {
+		name = section + "_" + key
+	}
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(name, ".", "_"))
+}
+
+func sourceKey(section, key string) string {
+	return section + "\x00" + key
+}
+
+// SourceOf 配置值来自哪一层，未配置时返回空字符串
+func SourceOf(section, key string) string {
+	lock.Lock()
+	defer lock.Unlock()
+	return sourceMap[sourceKey(section, key)]
+}
+
+// Explain 列出全部已配置的值及其来源，用于排查配置覆盖问题
+func Explain() []ValueSourceType {
+	file := GetInstance()
+	lock.Lock()
+	defer lock.Unlock()
+	var list []ValueSourceType
+	for _, section := range file.Sections() {
+		for _, key := range section.Keys() {
+			layer, ok := sourceMap[sourceKey(section.Name(), key.Name())]
+			// 跳过读取时自动创建的空键
+			if !ok {
+				continue
+			}
+			list = append(list, ValueSourceType{
+				Section: section.Name(),
+				Key:     key.Name(),
+				Value:   key.Value(),
+				Layer:   layer,
+			})
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Section < list[j].Section
+	})
+	return list
+}
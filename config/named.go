@@ -0,0 +1,42 @@
+package config
+
+import (
+	"sort"
+	"strings"
+)
+
+// 命名实例：[mysql] 为默认实例，[mysql.orders] 为名为 orders 的实例，
+// 与 ini 的子分组一致，命名实例中未配置的项继承默认实例的值
+
+// SectionName 命名实例对应的分组，name 为空时为默认实例，如 SectionName("mysql", "orders") 为 mysql.orders
+func SectionName(section, name string) string {
+	if "" == name {
+		return section
+	}
+	return section + "." + name
+}
+
+// HasSection 分组是否存在
+func HasSection(section string) bool {
+	if err := Load(); nil != err {
+		return false
+	}
+	return hasSection(GetInstance(), section)
+}
+
+// Names 分组下全部命名实例的名称，如 [mysql.orders]、[mysql.report] 返回 orders、report，不包含更深的子分组
+func Names(section string) []string {
+	if err := Load(); nil != err {
+		return nil
+	}
+	var nameList []string
+	for _, sectionName := range GetInstance().SectionStrings() {
+		name, ok := strings.CutPrefix(sectionName, section+".")
+		if !ok || "" == name || strings.Contains(name, ".") {
+			continue
+		}
+		nameList = append(nameList, name)
+	}
+	sort.Strings(nameList)
+	return nameList
+}
@@ -0,0 +1,28 @@
+package config
+
+import "testing"
+
+func TestNamed(t *testing.T) {
+	useConfigDir(t, map[string]string{
+		"dev.ini": "[core]\nserverName = demo\n[mysql]\nhost = main\nport = 3306\n[mysql.orders]\nhost = orders\n[mysql.report]\nport = 3307\n[mysql.orders.replicas.0]\nhost = r0\n",
+	})
+	nameList := Names("mysql")
+	if 2 != len(nameList) || "orders" != nameList[0] || "report" != nameList[1] {
+		t.Fatalf("%v", nameList)
+	}
+	if "mysql" != SectionName("mysql", "") || "mysql.orders" != SectionName("mysql", "orders") {
+		t.Fatal("分组名称错误")
+	}
+	if !HasSection("mysql.orders") || HasSection("mysql.typo") {
+		t.Fatal("分组是否存在判断错误")
+	}
+
+	// 命名实例未配置的项继承默认实例
+	namedConfig := &testReplicaType{}
+	if err := Bind("mysql.report", namedConfig); nil != err {
+		t.Fatal(err)
+	}
+	if "main" != namedConfig.Host || 3307 != namedConfig.Port {
+		t.Fatalf("%+v", namedConfig)
+	}
+}
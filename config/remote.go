@@ -0,0 +1,158 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mini-tiger/fast-api/core"
+	"github.com/mini-tiger/fast-api/dError"
+	"gopkg.in/ini.v1"
+)
+
+// RemoteFetchFuncType 读取远程配置，key 为 [config] remoteKey；返回值的 Layer 可为空
+type RemoteFetchFuncType func(ctx context.Context, key string) ([]ValueSourceType, error)
+
+// remoteFetchTimeout 单次读取远程配置的超时时间
+const remoteFetchTimeout = 5 * time.Second
+
+var remoteLock sync.Mutex
+
+// remoteSourceMap 已注册的远程配置来源，由 dbManager、cache 等组件注册
+var remoteSourceMap = map[string]RemoteFetchFuncType{}
+
+// remoteValueList 当前生效的远程配置，首次读取前为本地快照
+var remoteValueList []ValueSourceType
+
+// remoteLoaded 是否已读取过远程配置或本地快照
+var remoteLoaded bool
+
+var stopRemoteChan chan struct{}
+
+// RegisterRemoteSource 注册远程配置来源，[config] remote 为 name 时使用
+func RegisterRemoteSource(name string, fetch RemoteFetchFuncType) {
+	remoteLock.Lock()
+	defer remoteLock.Unlock()
+	remoteSourceMap[name] = fetch
+}
+
+// snapshotPath 远程配置的本地快照，远程来源不可用时使用
+func snapshotPath() string {
+	return core.Path("temp", fmt.Sprintf("config_remote_%s.json", core.Mode))
+}
+
+// remoteLayerPrefix 远程配置值的来源前缀，如 remote mysql
+const remoteLayerPrefix = "remote "
+
+// applyRemote 将远程配置覆盖到合并后的文件配置，远程配置位于文件之后、环境变量之前
+// 应用启动前远程来源依赖的组件尚未初始化，先使用上次保存的本地快照
+func applyRemote(file *ini.File, sourceMap map[string]string) {
+	remote := lookupValue(file, "config", "remote")
+	if "" == remote {
+		return
+	}
+	remoteLock.Lock()
+	defer remoteLock.Unlock()
+	if !remoteLoaded {
+		if data, err := os.ReadFile(snapshotPath()); nil == err {
+			_ = json.Unmarshal(data, &remoteValueList)
+		}
+		remoteLoaded = true
+	}
+	for _, value := range remoteValueList {
+		_, _ = file.Section(value.Section).NewKey(value.Key, value.Value)
+		sourceMap[sourceKey(value.Section, value.Key)] = remoteLayerPrefix + remote
+	}
+}
+
+// RefreshRemote 读取远程配置并热加载，成功后保存本地快照；
+// 远程来源不可用或新配置校验失败时保留当前配置并返回错误
+func RefreshRemote() error {
+	selfConfig, err := GetConfig()
+	if nil != err {
+		return err
+	}
+	if "" == selfConfig.Remote {
+		return nil
+	}
+	remoteLock.Lock()
+	fetch, ok := remoteSourceMap[selfConfig.Remote]
+	remoteLock.Unlock()
+	if !ok {
+		return dError.NewError(fmt.Sprintf("未知的远程配置来源 %s", selfConfig.Remote))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), remoteFetchTimeout)
+	defer cancel()
+	valueList, err := fetch(ctx, selfConfig.RemoteKey)
+	if nil != err {
+		return dError.NewError(fmt.Sprintf("读取远程配置 %s 出错，继续使用当前配置", selfConfig.Remote), err)
+	}
+
+	remoteLock.Lock()
+	oldValueList := remoteValueList
+	remoteValueList = valueList
+	remoteLock.Unlock()
+	if err := Reload(); nil != err {
+		remoteLock.Lock()
+		remoteValueList = oldValueList
+		remoteLock.Unlock()
+		return err
+	}
+
+	data, err := json.Marshal(valueList)
+	if nil == err {
+		err = os.WriteFile(snapshotPath(), data, 0600)
+	}
+	if nil != err {
+		return dError.NewError("保存远程配置快照出错", err)
+	}
+	return nil
+}
+
+// startRemote 立即读取一次远程配置，之后按 [config] remoteInterval 定期刷新
+func startRemote(selfConfig *ConfigType) {
+	if "" == selfConfig.Remote {
+		return
+	}
+	remoteLock.Lock()
+	if nil != stopRemoteChan {
+		remoteLock.Unlock()
+		return
+	}
+	stopChan := make(chan struct{})
+	stopRemoteChan = stopChan
+	remoteLock.Unlock()
+
+	refresh := func() {
+		if err := RefreshRemote(); nil != err {
+			notifyReloadError(err)
+		}
+	}
+	refresh()
+	go func() {
+		ticker := time.NewTicker(selfConfig.RemoteInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopChan:
+				return
+			case <-ticker.C:
+				refresh()
+			}
+		}
+	}()
+}
+
+// stopRemote 停止刷新远程配置
+func stopRemote() {
+	remoteLock.Lock()
+	defer remoteLock.Unlock()
+	if nil != stopRemoteChan {
+		close(stopRemoteChan)
+		stopRemoteChan = nil
+	}
+}
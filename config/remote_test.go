@@ -0,0 +1,69 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mini-tiger/fast-api/core"
+)
+
+func TestRemote(t *testing.T) {
+	useConfigDir(t, map[string]string{
+		"dev.ini": "[core]\nserverName = demo\n[config]\nremote = test\nremoteKey = demo-key\n[http]\naccessLog = true\n",
+	})
+	appPath := core.AppPath
+	core.AppPath = t.TempDir()
+	if err := os.Mkdir(core.Path("temp"), 0755); nil != err {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		core.AppPath = appPath
+		remoteValueList = nil
+		remoteLoaded = false
+		delete(remoteSourceMap, "test")
+	})
+
+	var fetchErr error
+	RegisterRemoteSource("test", func(ctx context.Context, key string) ([]ValueSourceType, error) {
+		if "demo-key" != key {
+			t.Errorf("remoteKey 错误 %s", key)
+		}
+		return []ValueSourceType{{Section: "http", Key: "accessLog", Value: "false"}, {Section: "feature", Key: "newCheckout", Value: "on"}, {Section: "feature", Key: "leak", Value: "${env:HOME}"}}, fetchErr
+	})
+
+	if err := RefreshRemote(); nil != err {
+		t.Fatal(err)
+	}
+	if "false" != GetInstance().Section("http").Key("accessLog").Value() || "on" != GetInstance().Section("feature").Key("newCheckout").Value() {
+		t.Fatal("远程配置应覆盖文件配置")
+	}
+	if "remote test" != SourceOf("http", "accessLog") {
+		t.Fatal(SourceOf("http", "accessLog"))
+	}
+	if "${env:HOME}" != GetInstance().Section("feature").Key("leak").Value() {
+		t.Fatal("远程配置的值不应解析引用")
+	}
+
+	// 远程来源不可用时保留当前配置
+	fetchErr = errors.New("unreachable")
+	if err := RefreshRemote(); nil == err {
+		t.Fatal("读取失败时应返回错误")
+	}
+	if "on" != GetInstance().Section("feature").Key("newCheckout").Value() {
+		t.Fatal("读取失败时应保留当前配置")
+	}
+
+	// 重新启动时先使用本地快照
+	if _, err := os.Stat(filepath.Join(core.AppPath, "temp", "config_remote_dev.json")); nil != err {
+		t.Fatal(err)
+	}
+	instance = nil
+	remoteValueList = nil
+	remoteLoaded = false
+	if "on" != GetInstance().Section("feature").Key("newCheckout").Value() {
+		t.Fatal("应读取本地快照")
+	}
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/mini-tiger/fast-api/dError"
+	"gopkg.in/ini.v1"
+)
+
+// MasterKeyEnv 解密 ENC(...) 配置值的主密钥，base64 编码的 16、24 或 32 字节，可通过 config keygen 生成
+const MasterKeyEnv = "APP_MASTER_KEY"
+
+// referencePattern 配置值中的引用：${env:NAME} 读取环境变量，${file:/run/secrets/x} 读取文件内容；$${ 表示 ${ 本身
+var referencePattern = regexp.MustCompile(`\$\$\{|\$\{(env|file):([^}]*)\}`)
+
+// resolveFile 解析全部配置值中的引用和加密值，错误汇总后一起返回；
+// 远程配置的值原样使用，避免能写远程配置的人通过 ${file:}、${env:} 读取本机文件和主密钥
+func resolveFile(file *ini.File, sourceMap map[string]string) error {
+	var errList []error
+	for _, section := range file.Sections() {
+		for _, key := range section.Keys() {
+			if strings.HasPrefix(sourceMap[sourceKey(section.Name(), key.Name())], remoteLayerPrefix) {
+				continue
+			}
+			value, err := ResolveValue(key.Value())
+			if nil != err {
+				errList = append(errList, fmt.Errorf("配置 [%s] %s 错误: %w", section.Name(), key.Name(), err))
+				continue
+			}
+			key.SetValue(value)
+		}
+	}
+	if 0 < len(errList) {
+		return dError.NewError("解析配置值出错", errList...)
+	}
+	return nil
+}
+
+// ResolveValue 替换值中的 ${env:NAME}、${file:path} 引用，结果为 ENC(...) 时使用主密钥解密
+func ResolveValue(value string) (string, error) {
+	var errList []error
+	value = referencePattern.ReplaceAllStringFunc(value, func(match string) string {
+		if "$${" == match {
+			return "${"
+		}
+		subMatch := referencePattern.FindStringSubmatch(match)
+		name := strings.TrimSpace(subMatch[2])
+		switch subMatch[1] {
+		case "env":
+			envValue, ok := os.LookupEnv(name)
+			if !ok {
+				errList = append(errList, fmt.Errorf("环境变量 %s 不存在", name))
+			}
+			return envValue
+		default:
+			data, err := os.ReadFile(name)
+			if nil != err {
+				errList = append(errList, fmt.Errorf("读取 %s 出错: %w", name, err))
+			}
+			return strings.TrimRight(string(data), "\r\n")
+		}
+	})
+	if 0 < len(errList) {
+		return "", errList[0]
+	}
+	if strings.HasPrefix(value, "ENC(") && strings.HasSuffix(value, ")") {
+		return Decrypt(value)
+	}
+	return value, nil
+}
+
+// masterKey 读取并校验主密钥
+func masterKey() ([]byte, error) {
+	encoded := os.Getenv(MasterKeyEnv)
+	if "" == encoded {
+		return nil, fmt.Errorf("未设置环境变量 %s", MasterKeyEnv)
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if nil != err {
+		return nil, fmt.Errorf("环境变量 %s 不是 base64 编码: %w", MasterKeyEnv, err)
+	}
+	if 16 != len(key) && 24 != len(key) && 32 != len(key) {
+		return nil, fmt.Errorf("环境变量 %s 长度必须是 16、24 或 32 字节", MasterKeyEnv)
+	}
+	return key, nil
+}
+
+func newGCM() (cipher.AEAD, error) {
+	key, err := masterKey()
+	if nil != err {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if nil != err {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Encrypt 使用主密钥（AES-GCM）加密，返回可直接写入配置的 ENC(...)
+func Encrypt(plain string) (string, error) {
+	gcm, err := newGCM()
+	if nil != err {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); nil != err {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plain), nil)
+	return "ENC(" + base64.StdEncoding.EncodeToString(sealed) + ")", nil
+}
+
+// Decrypt 使用主密钥解密 ENC(...)
+func Decrypt(value string) (string, error) {
+	encoded := strings.TrimSuffix(strings.TrimPrefix(value, "ENC("), ")")
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if nil != err {
+		return "", fmt.Errorf("加密值不是 base64 编码: %w", err)
+	}
+	gcm, err := newGCM()
+	if nil != err {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("加密值长度错误")
+	}
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if nil != err {
+		return "", fmt.Errorf("解密失败，请检查 %s: %w", MasterKeyEnv, err)
+	}
+	return string(plain), nil
+}
+
+// GenerateKey 生成 32 字节的随机主密钥，返回 base64 编码
+func GenerateKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); nil != err {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
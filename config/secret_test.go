@@ -0,0 +1,62 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveValue(t *testing.T) {
+	key, err := GenerateKey()
+	if nil != err {
+		t.Fatal(err)
+	}
+	t.Setenv(MasterKeyEnv, key)
+	t.Setenv("TEST_DB_PASSWORD", "p#1")
+	secretPath := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(secretPath, []byte("from-file\n"), 0600); nil != err {
+		t.Fatal(err)
+	}
+	encrypted, err := Encrypt("s3cret")
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	caseList := [][2]string{
+		{"${env:TEST_DB_PASSWORD}", "p#1"},
+		{"${file:" + secretPath + "}", "from-file"},
+		{"user-${env:TEST_DB_PASSWORD}-x", "user-p#1-x"},
+		{"$${env:TEST_DB_PASSWORD}", "${env:TEST_DB_PASSWORD}"},
+		{encrypted, "s3cret"},
+		{"plain", "plain"},
+	}
+	for _, c := range caseList {
+		value, err := ResolveValue(c[0])
+		if nil != err || c[1] != value {
+			t.Errorf("%s 期望 %s 实际 %s %v", c[0], c[1], value, err)
+		}
+	}
+
+	for _, value := range []string{"${env:TEST_NOT_EXIST}", "${file:/not/exist}", "ENC(abc)"} {
+		if _, err := ResolveValue(value); nil == err {
+			t.Errorf("%s 应返回错误", value)
+		}
+	}
+
+	// 配置文件中的引用在读取时解析，主密钥错误时读取失败
+	useConfigDir(t, map[string]string{
+		"dev.ini": "[core]\nserverName = demo\n[mysql]\npassword = " + encrypted + "\nusername = ${env:TEST_DB_PASSWORD}\n",
+	})
+	if err := Load(); nil != err {
+		t.Fatal(err)
+	}
+	if "s3cret" != GetInstance().Section("mysql").Key("password").Value() || "p#1" != GetInstance().Section("mysql").Key("username").Value() {
+		t.Fatal(GetInstance().Section("mysql").KeysHash())
+	}
+	otherKey, _ := GenerateKey()
+	t.Setenv(MasterKeyEnv, otherKey)
+	instance = nil
+	if err := Load(); nil == err {
+		t.Fatal("主密钥错误时应返回错误")
+	}
+}
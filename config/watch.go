@@ -0,0 +1,226 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mini-tiger/fast-api/dError"
+	"gopkg.in/ini.v1"
+)
+
+// reloadLock 保证同一时间只有一次热加载
+var reloadLock sync.Mutex
+
+var subscriberLock sync.Mutex
+var subscriberMap = map[string][]func(){}
+var reloadErrorHookList []func(err error)
+
+// OnChange 订阅分组变更，热加载后分组内任一值变化时调用，fn 中通过 Bind 读取最新值
+func OnChange(section string, fn func()) {
+	subscriberLock.Lock()
+	defer subscriberLock.Unlock()
+	subscriberMap[section] = append(subscriberMap[section], fn)
+}
+
+// OnReloadError 订阅热加载失败，新配置被拒绝时调用，如 dLogger 记录错误日志
+func OnReloadError(fn func(err error)) {
+	subscriberLock.Lock()
+	defer subscriberLock.Unlock()
+	reloadErrorHookList = append(reloadErrorHookList, fn)
+}
+
+// Reload 重新读取各层配置，校验通过后整体替换并通知变更的分组；校验失败时保留旧配置并返回错误
+func Reload() error {
+	reloadLock.Lock()
+	defer reloadLock.Unlock()
+	if err := Load(); nil != err {
+		return err
+	}
+
+	lock.Lock()
+	oldFile := instance
+	lock.Unlock()
+	newFile, newSourceMap, err := loadLayers()
+	if nil == err {
+		err = validateFile(newFile, true)
+	}
+	// 全局时区在启动时设置到 time.Local，调度器和数据库连接也已按其创建，运行中修改会产生数据竞争
+	if nil == err {
+		if timezone := lookupValue(newFile, "core", "timezone"); timezone != lookupValue(oldFile, "core", "timezone") {
+			err = dError.NewError(fmt.Sprintf("配置 [core] timezone 修改为 %s 需要重启生效", timezone))
+		}
+	}
+	if nil != err {
+		err = dError.NewError("配置热加载失败，继续使用旧配置", err)
+		notifyReloadError(err)
+		return err
+	}
+
+	lock.Lock()
+	instance = newFile
+	sourceMap = newSourceMap
+	lock.Unlock()
+
+	notifyChange(changedSections(oldFile, newFile))
+	return nil
+}
+
+// changedSections 新旧配置中有差异的分组
+func changedSections(oldFile, newFile *ini.File) []string {
+	sectionMap := map[string]bool{}
+	for _, file := range []*ini.File{oldFile, newFile} {
+		for _, name := range file.SectionStrings() {
+			sectionMap[name] = true
+		}
+	}
+	var changedList []string
+	for name := range sectionMap {
+		if !sameHash(sectionHash(oldFile, name), sectionHash(newFile, name)) {
+			changedList = append(changedList, name)
+		}
+	}
+	return changedList
+}
+
+func sectionHash(file *ini.File, name string) map[string]string {
+	section, err := file.GetSection(name)
+	if nil != err {
+		return nil
+	}
+	return section.KeysHash()
+}
+
+func sameHash(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		if otherValue, ok := b[key]; !ok || otherValue != value {
+			return false
+		}
+	}
+	return true
+}
+
+// notifyChange 通知变更分组的订阅者，单个订阅者 panic 不影响其他订阅者
+func notifyChange(sectionList []string) {
+	for _, section := range sectionList {
+		subscriberLock.Lock()
+		fnList := append([]func(){}, subscriberMap[section]...)
+		subscriberLock.Unlock()
+		for _, fn := range fnList {
+			func() {
+				defer func() {
+					if recovered := recover(); nil != recovered {
+						notifyReloadError(dError.NewError(fmt.Sprintf("配置 [%s] 变更处理出错: %v", section, recovered)))
+					}
+				}()
+				fn()
+			}()
+		}
+	}
+}
+
+func notifyReloadError(err error) {
+	subscriberLock.Lock()
+	hookList := append([]func(err error){}, reloadErrorHookList...)
+	subscriberLock.Unlock()
+	if 0 == len(hookList) {
+		_, _ = fmt.Fprintln(os.Stderr, err.Error())
+	}
+	for _, hook := range hookList {
+		hook(err)
+	}
+}
+
+// fileStateType 配置文件状态，用于轮询判断是否修改
+type fileStateType struct {
+	exist   bool
+	modTime time.Time
+	size    int64
+}
+
+func layerStates() map[string]fileStateType {
+	stateMap := map[string]fileStateType{}
+	for _, layer := range layerList() {
+		// 新增其他格式的文件也视为修改
+		for _, path := range layer.candidates() {
+			info, err := os.Stat(path)
+			if nil != err {
+				stateMap[path] = fileStateType{}
+				continue
+			}
+			stateMap[path] = fileStateType{exist: true, modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+	return stateMap
+}
+
+// Watch 按 interval 轮询配置文件，修改后自动 Reload，返回停止函数，停止时等待进行中的 Reload 完成
+func Watch(interval time.Duration) func() {
+	stopChan := make(chan struct{})
+	doneChan := make(chan struct{})
+	var stopOnce sync.Once
+	lastStates := layerStates()
+	go func() {
+		defer close(doneChan)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopChan:
+				return
+			case <-ticker.C:
+				states := layerStates()
+				if sameStates(lastStates, states) {
+					continue
+				}
+				lastStates = states
+				_ = Reload()
+			}
+		}
+	}()
+	return func() {
+		stopOnce.Do(func() {
+			close(stopChan)
+		})
+		<-doneChan
+	}
+}
+
+func sameStates(a, b map[string]fileStateType) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, state := range a {
+		other, ok := b[path]
+		if !ok || other.exist != state.exist || other.size != state.size || !other.modTime.Equal(state.modTime) {
+			return false
+		}
+	}
+	return true
+}
+
+var stopWatch func()
+var watchLock sync.Mutex
+
+// startWatch 按 [config] watchInterval 开启热加载
+func startWatch(interval time.Duration) {
+	watchLock.Lock()
+	defer watchLock.Unlock()
+	if 0 < interval && nil == stopWatch {
+		stopWatch = Watch(interval)
+	}
+}
+
+// stopWatching 停止热加载
+func stopWatching() {
+	watchLock.Lock()
+	defer watchLock.Unlock()
+	if nil != stopWatch {
+		stopWatch()
+		stopWatch = nil
+	}
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReload(t *testing.T) {
+	dir := useConfigDir(t, map[string]string{
+		"dev.ini": "[core]\nserverName = demo\n[ok]\nhost = db\n[other]\nname = a\n",
+	})
+	t.Cleanup(func() {
+		bindingList = nil
+		subscriberMap = map[string][]func(){}
+		reloadErrorHookList = nil
+	})
+	Register("ok", &testBindType{})
+
+	changedMap := map[string]int{}
+	OnChange("ok", func() { changedMap["ok"]++ })
+	OnChange("other", func() { changedMap["other"]++ })
+	var reloadErr error
+	OnReloadError(func(err error) { reloadErr = err })
+	if err := Load(); nil != err {
+		t.Fatal(err)
+	}
+
+	writeFile := func(content string) {
+		if err := os.WriteFile(filepath.Join(dir, "dev.ini"), []byte(content), 0666); nil != err {
+			t.Fatal(err)
+		}
+	}
+
+	// 只通知有变化的分组
+	writeFile("[core]\nserverName = demo\n[ok]\nhost = db2\n[other]\nname = a\n")
+	if err := Reload(); nil != err {
+		t.Fatal(err)
+	}
+	if 1 != changedMap["ok"] || 0 != changedMap["other"] {
+		t.Fatalf("%v", changedMap)
+	}
+	if "db2" != GetInstance().Section("ok").Key("host").Value() {
+		t.Fatal("应使用新配置")
+	}
+
+	// 校验不通过时保留旧配置
+	writeFile("[core]\nserverName = demo\n[ok]\nport = 70000\n")
+	if err := Reload(); nil == err || nil == reloadErr {
+		t.Fatal("不合法的配置应被拒绝")
+	}
+	if "db2" != GetInstance().Section("ok").Key("host").Value() || 1 != changedMap["ok"] {
+		t.Fatal("应保留旧配置")
+	}
+
+	// 全局时区需要重启生效
+	writeFile("[core]\nserverName = demo\ntimezone = UTC\n[ok]\nhost = db3\n[other]\nname = a\n")
+	if err := Reload(); nil == err || "db2" != GetInstance().Section("ok").Key("host").Value() {
+		t.Fatal("修改全局时区的配置应被拒绝", err)
+	}
+
+	// 文件无法解析时同样保留旧配置，如保存到一半
+	writeFile("[core\nbroken")
+	if err := Reload(); nil == err || "db2" != GetInstance().Section("ok").Key("host").Value() {
+		t.Fatal("无法解析的配置应被拒绝", err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dir := useConfigDir(t, map[string]string{
+		"dev.ini": "[core]\nserverName = demo\n[ok]\nhost = db\n",
+	})
+	t.Cleanup(func() {
+		subscriberMap = map[string][]func(){}
+	})
+	changed := make(chan struct{})
+	var once sync.Once
+	OnChange("ok", func() { once.Do(func() { close(changed) }) })
+	if err := Load(); nil != err {
+		t.Fatal(err)
+	}
+	stop := Watch(10 * time.Millisecond)
+	defer stop()
+
+	if err := os.WriteFile(filepath.Join(dir, "dev.ini"), []byte("[core]\nserverName = demo\n[ok]\nhost = db-new\n"), 0666); nil != err {
+		t.Fatal(err)
+	}
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("修改配置文件后应自动热加载")
+	}
+	if "db-new" != GetInstance().Section("ok").Key("host").Value() {
+		t.Fatal("应使用新配置")
+	}
+}
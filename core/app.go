@@ -0,0 +1,246 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mini-tiger/fast-api/dError"
+)
+
+// 内置组件名称，各子系统包在 init 中以这些名称注册自己
+const (
+	ComponentConfig = "config"
+	ComponentMysql  = "mysql"
+	ComponentRedis  = "redis"
+	ComponentOss    = "oss"
+	ComponentLogger = "logger"
+	ComponentCron   = "cron"
+	ComponentHttp   = "http"
+)
+
+// ComponentType 可按需初始化的子系统
+type ComponentType struct {
+	// Name 组件名称，全局唯一
+	Name string
+	// Order 初始化顺序，数值越小越先初始化
+	Order int
+	// Depends 依赖的组件，初始化本组件前会先初始化依赖，依赖的 Order 必须更小
+	Depends []string
+	// Validate 初始化前校验配置，所有组件校验完成后汇总错误，有错误时不会执行任何 Init，可为空
+	Validate func() error
+	// Init 初始化组件，返回错误时启动失败
+	Init func() error
+	// Start 应用运行时启动组件（如开始调度、开始监听），可为空
+	Start func() error
+	// Stop 应用退出时停止组件，需在 ctx 超时前返回，可为空
+	Stop func(ctx context.Context) error
+}
+
+var componentLock sync.RWMutex
+var componentMap = map[string]*ComponentType{}
+
+// RegisterComponent 注册组件，仅登记不初始化，重复注册以后者为准
+func RegisterComponent(component *ComponentType) {
+	componentLock.Lock()
+	defer componentLock.Unlock()
+	componentMap[component.Name] = component
+}
+
+// getComponent 获取已注册的组件
+func getComponent(name string) (*ComponentType, bool) {
+	componentLock.RLock()
+	defer componentLock.RUnlock()
+	component, ok := componentMap[name]
+	return component, ok
+}
+
+// componentNames 全部已注册组件的名称
+func componentNames() []string {
+	componentLock.RLock()
+	defer componentLock.RUnlock()
+	nameList := make([]string, 0, len(componentMap))
+	for name := range componentMap {
+		nameList = append(nameList, name)
+	}
+	return nameList
+}
+
+// OptionsType 启动参数
+type OptionsType struct {
+	// Components 需要初始化的组件，为空时初始化全部已注册组件
+	Components []string
+	// StopTimeout 退出时每个步骤的超时时间，默认 10 秒
+	StopTimeout time.Duration
+}
+
+// AppType 应用实例，负责按顺序初始化各组件
+type AppType struct {
+	options OptionsType
+	lock    sync.Mutex
+	// initList 已初始化成功的组件，按初始化顺序排列
+	initList []*ComponentType
+	initMap  map[string]bool
+	// stopping 是否正在退出
+	stopping atomic.Bool
+	stopOnce sync.Once
+}
+
+var app *AppType
+
+// Bootstrap 按需初始化组件，所有组件的错误会汇总后一起返回
+func Bootstrap(options OptionsType) (*AppType, error) {
+	if 0 >= options.StopTimeout {
+		options.StopTimeout = 10 * time.Second
+	}
+	app = &AppType{
+		options: options,
+		initMap: map[string]bool{},
+	}
+
+	var errList []error
+	if err := ResolveMode(); nil != err {
+		errList = append(errList, err)
+	}
+	if nil != appPathErr {
+		errList = append(errList, appPathErr)
+	}
+	// 创建运行时目录
+	errList = append(errList, initDir()...)
+
+	nameList := options.Components
+	if 0 == len(nameList) {
+		nameList = componentNames()
+	}
+	if err := app.Require(nameList...); nil != err {
+		errList = append(errList, err.SourceErr...)
+	}
+
+	if 0 < len(errList) {
+		return app, dError.NewError("应用启动失败", errList...)
+	}
+	return app, nil
+}
+
+// GetApp 获取最近一次 Bootstrap 创建的应用实例，未启动时返回 nil
+func GetApp() *AppType {
+	return app
+}
+
+// Require 初始化指定组件及其依赖，已初始化的组件会跳过
+func (a *AppType) Require(nameList ...string) *dError.ErrorType {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	componentList, errList := resolveComponents(nameList)
+
+	// 先校验全部组件，依赖校验失败的组件不再重复报错
+	validateFailedMap := map[string]bool{}
+	var validateErrList []error
+	for _, component := range componentList {
+		if a.initMap[component.Name] {
+			continue
+		}
+		for _, depend := range component.Depends {
+			if validateFailedMap[depend] {
+				validateFailedMap[component.Name] = true
+			}
+		}
+		if validateFailedMap[component.Name] || nil == component.Validate {
+			continue
+		}
+		if err := component.Validate(); nil != err {
+			validateErrList = append(validateErrList, fmt.Errorf("组件 %s 配置错误: %w", component.Name, err))
+			validateFailedMap[component.Name] = true
+		}
+	}
+	if 0 < len(validateErrList) {
+		return dError.NewError("组件初始化失败", append(errList, validateErrList...)...)
+	}
+
+	for _, component := range componentList {
+		if a.initMap[component.Name] {
+			continue
+		}
+		if failed := a.failedDepends(component); "" != failed {
+			errList = append(errList, fmt.Errorf("组件 %s 的依赖 %s 初始化失败", component.Name, failed))
+			continue
+		}
+		if nil != component.Init {
+			if err := component.Init(); nil != err {
+				errList = append(errList, fmt.Errorf("组件 %s 初始化失败: %w", component.Name, err))
+				continue
+			}
+		}
+		a.initMap[component.Name] = true
+		a.initList = append(a.initList, component)
+	}
+
+	if 0 < len(errList) {
+		return dError.NewError("组件初始化失败", errList...)
+	}
+	return nil
+}
+
+// Initialized 判断组件是否已初始化
+func (a *AppType) Initialized(name string) bool {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.initMap[name]
+}
+
+// failedDepends 返回第一个未初始化成功的依赖
+func (a *AppType) failedDepends(component *ComponentType) string {
+	for _, depend := range component.Depends {
+		if !a.initMap[depend] {
+			return depend
+		}
+	}
+	return ""
+}
+
+// resolveComponents 展开依赖并按 Order 排序
+func resolveComponents(nameList []string) ([]*ComponentType, []error) {
+	var errList []error
+	resolved := map[string]*ComponentType{}
+
+	var visit func(name string, path []string)
+	visit = func(name string, path []string) {
+		if _, ok := resolved[name]; ok {
+			return
+		}
+		for _, parent := range path {
+			if parent == name {
+				errList = append(errList, fmt.Errorf("组件 %s 存在循环依赖", name))
+				return
+			}
+		}
+		component, ok := getComponent(name)
+		if !ok {
+			errList = append(errList, fmt.Errorf("组件 %s 未注册，请确认已导入对应的包", name))
+			return
+		}
+		for _, depend := range component.Depends {
+			visit(depend, append(path, name))
+		}
+		resolved[name] = component
+	}
+	for _, name := range nameList {
+		visit(name, nil)
+	}
+
+	componentList := make([]*ComponentType, 0, len(resolved))
+	for _, component := range resolved {
+		componentList = append(componentList, component)
+	}
+	sort.Slice(componentList, func(i, j int) bool {
+		if componentList[i].Order == componentList[j].Order {
+			return componentList[i].Name < componentList[j].Name
+		}
+		return componentList[i].Order < componentList[j].Order
+	})
+	return componentList, errList
+}
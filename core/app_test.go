@@ -0,0 +1,101 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// useTempAppPath 测试时使用临时目录作为项目根目录
+func useTempAppPath(t *testing.T) {
+	AppPath = t.TempDir()
+	appPathErr = nil
+}
+
+func TestBootstrapOrder(t *testing.T) {
+	useTempAppPath(t)
+	var initOrder []string
+	newComponent := func(name string, order int, depends ...string) *ComponentType {
+		return &ComponentType{
+			Name:    name,
+			Order:   order,
+			Depends: depends,
+			Init: func() error {
+				initOrder = append(initOrder, name)
+				return nil
+			},
+		}
+	}
+	RegisterComponent(newComponent("test.b", 2, "test.a"))
+	RegisterComponent(newComponent("test.a", 1))
+	RegisterComponent(newComponent("test.c", 3))
+
+	application, err := Bootstrap(OptionsType{Components: []string{"test.b"}})
+	if nil != err {
+		t.Fatal(err)
+	}
+	if 2 != len(initOrder) || "test.a" != initOrder[0] || "test.b" != initOrder[1] {
+		t.Fatalf("初始化顺序错误: %v", initOrder)
+	}
+	if application.Initialized("test.c") {
+		t.Fatal("未请求的组件不应初始化")
+	}
+
+	// 已初始化的组件不会重复初始化
+	if err := application.Require("test.a", "test.c"); nil != err {
+		t.Fatal(err)
+	}
+	if 3 != len(initOrder) || "test.c" != initOrder[2] {
+		t.Fatalf("按需初始化错误: %v", initOrder)
+	}
+}
+
+func TestBootstrapAggregateError(t *testing.T) {
+	useTempAppPath(t)
+	errA := errors.New("a failed")
+	RegisterComponent(&ComponentType{Name: "test.fail", Order: 1, Init: func() error { return errA }})
+	RegisterComponent(&ComponentType{Name: "test.child", Order: 2, Depends: []string{"test.fail"}})
+
+	_, err := Bootstrap(OptionsType{Components: []string{"test.child", "test.missing"}})
+	if nil == err {
+		t.Fatal("应返回错误")
+	}
+	if !errors.Is(err, errA) {
+		t.Fatalf("汇总错误中缺少组件错误: %v", err)
+	}
+	if 3 != len(err.(interface{ Unwrap() []error }).Unwrap()) {
+		t.Fatalf("应汇总3个错误: %v", err)
+	}
+}
+
+func TestBootstrapValidate(t *testing.T) {
+	useTempAppPath(t)
+	initCount := 0
+	newComponent := func(name string, validateErr error) *ComponentType {
+		return &ComponentType{
+			Name:  name,
+			Order: 1,
+			Validate: func() error {
+				return validateErr
+			},
+			Init: func() error {
+				initCount++
+				return nil
+			},
+		}
+	}
+	RegisterComponent(newComponent("validate.ok", nil))
+	RegisterComponent(newComponent("validate.a", errors.New("缺少配置 [a] host")))
+	RegisterComponent(newComponent("validate.b", errors.New("缺少配置 [b] host")))
+
+	_, err := Bootstrap(OptionsType{Components: []string{"validate.ok", "validate.a", "validate.b"}})
+	if nil == err {
+		t.Fatal("应返回错误")
+	}
+	if !strings.Contains(err.Error(), "[a]") || !strings.Contains(err.Error(), "[b]") {
+		t.Fatalf("应汇总全部组件的配置错误: %v", err)
+	}
+	if 0 != initCount {
+		t.Fatal("配置校验失败时不应初始化任何组件")
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"time"
 
 	"github.com/mini-tiger/fast-api/dError"
 )
@@ -12,56 +11,118 @@ import (
 // AppPath 项目根目录 示例： /Users/project/go/src/go-server-image
 var AppPath = "/app"
 
-// Mode 项目运行环境 [dev, test, produce]
-var Mode = Dev
-
-type ModeType string
-
-const (
-	Dev     ModeType = "dev"
-	Test    ModeType = "test"
-	Produce ModeType = "produce"
-)
+// appPathErr 查找项目根目录失败的原因，由 Bootstrap 返回
+var appPathErr error
 
 func init() {
-	time.Local, _ = time.LoadLocation("Asia/Shanghai")
+	// 默认时区，配置文件中 [core] timezone 可覆盖；加载失败时保持系统时区，不会置为 nil
+	_ = SetTimezone(DefaultTimezone)
 	// 初始化项目根目录
 	initAppPth()
-	// 初始化运行环境
-	initMode()
+	// 初始化运行环境，此时自定义环境尚未注册，启动时会重新解析
+	_ = ResolveMode()
 }
 
+// AppPathEnv 指定项目根目录的环境变量
+const AppPathEnv = "APP_PATH"
+
+// MarkerFile 项目根目录标记文件，部署时没有 conf 目录可放置该文件
+const MarkerFile = ".fastapi"
+
+// initAppPth 查找项目根目录
+// 顺序：APP_PATH 环境变量 > 可执行文件所在目录（含 conf 目录或 .fastapi 标记）
+// > 从工作目录向上查找 conf 目录或 .fastapi 标记 > 从工作目录向上查找 main.go
 func initAppPth() {
+	appPath, err := findAppPath()
+	if nil != err {
+		appPathErr = err
+		return
+	}
+	AppPath = appPath
+}
+
+func findAppPath() (string, error) {
+	if envPath := os.Getenv(AppPathEnv); "" != envPath {
+		absPath, err := filepath.Abs(envPath)
+		if nil != err {
+			return "", dError.NewError(fmt.Sprintf("环境变量 %s 无效", AppPathEnv), err)
+		}
+		if info, err := os.Stat(absPath); nil != err || !info.IsDir() {
+			return "", dError.NewError(fmt.Sprintf("环境变量 %s 指定的目录 %s 不存在", AppPathEnv, absPath))
+		}
+		return absPath, nil
+	}
+
+	// 编译后的二进制：可执行文件旁边通常放置 conf 目录
+	if exePath, err := os.Executable(); nil == err {
+		if realPath, err := filepath.EvalSymlinks(exePath); nil == err {
+			exePath = realPath
+		}
+		if exeDir := filepath.Dir(exePath); hasMarker(exeDir) {
+			return exeDir, nil
+		}
+	}
+
 	dirString, err := os.Getwd()
 	if nil != err {
-		panic(dError.NewError("找不到项目根目录！", err))
+		return "", dError.NewError("找不到项目根目录！", err)
+	}
+	if markerDir, ok := lookupUp(dirString, hasMarker); ok {
+		return markerDir, nil
+	}
+	if mainDir, ok := lookupUp(dirString, func(dir string) bool {
+		return FileExist(filepath.Join(dir, "main.go"))
+	}); ok {
+		return mainDir, nil
+	}
+	return "", dError.NewError(fmt.Sprintf("找不到项目根目录！请设置环境变量 %s，或在项目根目录放置 conf 目录或 %s 文件", AppPathEnv, MarkerFile))
+}
+
+// hasMarker 目录下是否有 conf 目录或 .fastapi 标记文件
+func hasMarker(dir string) bool {
+	if FileExist(filepath.Join(dir, MarkerFile)) {
+		return true
 	}
+	info, err := os.Stat(filepath.Join(dir, "conf"))
+	return nil == err && info.IsDir()
+}
+
+// lookupUp 从 dir 开始逐级向上查找满足条件的目录
+func lookupUp(dir string, match func(dir string) bool) (string, bool) {
 	for {
-		mainPath := fmt.Sprintf("%s/main.go", dirString)
-		if FileExist(mainPath) {
-			AppPath = dirString
-			return
+		if match(dir) {
+			return dir, true
 		}
-		// 直到根目录依然没有找到main.go
-		if "/" == dirString {
-			panic(dError.NewError("找不到项目根目录！", err))
+		parent := filepath.Dir(dir)
+		// 直到根目录依然没有找到
+		if parent == dir {
+			return "", false
 		}
-		dirString = filepath.Dir(dirString)
+		dir = parent
 	}
 }
 
-func initMode() {
-	Mode = Dev
-	if len(os.Args) <= 1 {
-		return
+// ConfigDirEnv 指定配置文件目录的环境变量
+const ConfigDirEnv = "APP_CONFIG_DIR"
+
+// ConfigDir 配置文件目录，为空时依次使用 APP_CONFIG_DIR 环境变量、项目根目录下的 conf 目录
+var ConfigDir = ""
+
+// ConfigPath 拼接配置文件目录下的路径，如 ConfigPath("dev.ini")
+func ConfigPath(elem ...string) string {
+	dir := ConfigDir
+	if "" == dir {
+		dir = os.Getenv(ConfigDirEnv)
 	}
-	// 验证输入是否为有效的 ModeType 值
-	modeStr := os.Args[1]
-	switch ModeType(modeStr) {
-	case Dev, Test, Produce:
-		Mode = ModeType(modeStr)
-	default:
+	if "" == dir {
+		dir = Path("conf")
 	}
+	return filepath.Join(append([]string{dir}, elem...)...)
+}
+
+// Path 拼接项目根目录下的路径，如 Path("conf", "dev.ini")
+func Path(elem ...string) string {
+	return filepath.Join(append([]string{AppPath}, elem...)...)
 }
 
 // FileExist 判断文件是否存在
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFindAppPath(t *testing.T) {
+	root := t.TempDir()
+	subDir := filepath.Join(root, "internal", "service")
+	if err := os.MkdirAll(subDir, 0777); nil != err {
+		t.Fatal(err)
+	}
+	t.Chdir(subDir)
+
+	// 没有任何标记时找不到
+	t.Setenv(AppPathEnv, "")
+	if _, err := findAppPath(); nil == err {
+		t.Fatal("没有标记时应返回错误")
+	}
+
+	// main.go 兜底
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main"), 0666); nil != err {
+		t.Fatal(err)
+	}
+	if appPath, err := findAppPath(); nil != err || !sameDir(appPath, root) {
+		t.Fatalf("应找到 main.go 所在目录，实际 %s %v", appPath, err)
+	}
+
+	// 标记文件优先于 main.go
+	if err := os.WriteFile(filepath.Join(root, "internal", MarkerFile), nil, 0666); nil != err {
+		t.Fatal(err)
+	}
+	if appPath, err := findAppPath(); nil != err || !sameDir(appPath, filepath.Join(root, "internal")) {
+		t.Fatalf("应找到标记文件所在目录，实际 %s %v", appPath, err)
+	}
+
+	// 环境变量优先
+	t.Setenv(AppPathEnv, subDir)
+	if appPath, err := findAppPath(); nil != err || !sameDir(appPath, subDir) {
+		t.Fatalf("应使用环境变量，实际 %s %v", appPath, err)
+	}
+	t.Setenv(AppPathEnv, filepath.Join(root, "missing"))
+	if _, err := findAppPath(); nil == err {
+		t.Fatal("环境变量指定的目录不存在时应返回错误")
+	}
+}
+
+func TestPath(t *testing.T) {
+	oldPath := AppPath
+	defer func() { AppPath = oldPath }()
+	AppPath = "/app"
+	if "/app/conf/dev.ini" != Path("conf", "dev.ini") {
+		t.Fatal(Path("conf", "dev.ini"))
+	}
+}
+
+func sameDir(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return nil == errA && nil == errB && os.SameFile(infoA, infoB)
+}
+
+func TestSetTimezone(t *testing.T) {
+	oldLocal := time.Local
+	defer func() { time.Local = oldLocal }()
+
+	if err := SetTimezone("America/New_York"); nil != err {
+		t.Fatal(err)
+	}
+	if "America/New_York" != time.Local.String() {
+		t.Fatal(time.Local)
+	}
+	if err := SetTimezone("Asia/Shangai"); nil == err {
+		t.Fatal("无效时区应返回错误")
+	}
+	if nil == time.Local || "America/New_York" != time.Local.String() {
+		t.Fatal("设置失败时 time.Local 应保持不变")
+	}
+}
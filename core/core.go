@@ -6,14 +6,25 @@ import (
 	"time"
 )
 
-func init() {
-	// 创建temp文件夹
-	_ = os.Mkdir(fmt.Sprintf("%s/log", AppPath), 0777)
-	_ = os.Mkdir(fmt.Sprintf("%s/temp", AppPath), 0777)
+// initDir 创建运行时目录
+func initDir() []error {
+	var errList []error
+	for _, dir := range []string{"log", "temp"} {
+		if err := os.MkdirAll(Path(dir), 0777); nil != err {
+			errList = append(errList, err)
+		}
+	}
+	return errList
 }
 
 func Start() {
+	_ = initDir()
 	fmt.Printf("当前时间：%s\n", time.Now().Format("2006-01-02 15:04:05"))
 	fmt.Printf("运行路径：%s\n", AppPath)
 	fmt.Printf("运行环境：%s\n", Mode)
+	info := GetBuildInfo()
+	fmt.Printf("版本：%s\n", info.Version)
+	fmt.Printf("提交：%s\n", info.GitCommit)
+	fmt.Printf("构建时间：%s\n", info.BuildTime)
+	fmt.Printf("Go版本：%s\n", info.GoVersion)
 }
@@ -0,0 +1,110 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 健康状态
+const (
+	HealthUp   = "up"
+	HealthDown = "down"
+)
+
+// HealthCheckFuncType 健康检查函数，detail 为附加信息（如连接池统计），需在 ctx 超时前返回
+type HealthCheckFuncType func(ctx context.Context) (detail any, err error)
+
+// HealthCheckResultType 单项检查结果
+type HealthCheckResultType struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Detail   any    `json:"detail,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// HealthReportType 汇总报告，任一检查失败时 Status 为 down
+type HealthReportType struct {
+	Status string                  `json:"status"`
+	Checks []HealthCheckResultType `json:"checks"`
+}
+
+var healthLock sync.RWMutex
+var healthCheckMap = map[string]HealthCheckFuncType{}
+
+// RegisterHealthCheck 注册健康检查，重复注册以后者为准
+func RegisterHealthCheck(name string, fn HealthCheckFuncType) {
+	healthLock.Lock()
+	defer healthLock.Unlock()
+	healthCheckMap[name] = fn
+}
+
+// CheckHealth 并发执行全部健康检查，每项检查单独计算超时
+func CheckHealth(ctx context.Context, timeout time.Duration) HealthReportType {
+	healthLock.RLock()
+	checkMap := make(map[string]HealthCheckFuncType, len(healthCheckMap))
+	for name, fn := range healthCheckMap {
+		checkMap[name] = fn
+	}
+	healthLock.RUnlock()
+
+	report := HealthReportType{Status: HealthUp}
+	resultChan := make(chan HealthCheckResultType, len(checkMap))
+	for name, fn := range checkMap {
+		go func() {
+			resultChan <- runHealthCheck(ctx, timeout, name, fn)
+		}()
+	}
+	for range checkMap {
+		result := <-resultChan
+		if HealthUp != result.Status {
+			report.Status = HealthDown
+		}
+		report.Checks = append(report.Checks, result)
+	}
+	sort.Slice(report.Checks, func(i, j int) bool {
+		return report.Checks[i].Name < report.Checks[j].Name
+	})
+	return report
+}
+
+// runHealthCheck 执行单项检查，超时或 panic 均视为失败
+func runHealthCheck(ctx context.Context, timeout time.Duration, name string, fn HealthCheckFuncType) HealthCheckResultType {
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	begin := time.Now()
+	result := HealthCheckResultType{Name: name, Status: HealthUp}
+
+	type doneType struct {
+		detail any
+		err    error
+	}
+	done := make(chan doneType, 1)
+	go func() {
+		defer func() {
+			if recovered := recover(); nil != recovered {
+				done <- doneType{err: fmt.Errorf("panic: %v", recovered)}
+			}
+		}()
+		detail, err := fn(checkCtx)
+		done <- doneType{detail: detail, err: err}
+	}()
+
+	select {
+	case checkDone := <-done:
+		result.Detail = checkDone.detail
+		if nil != checkDone.err {
+			result.Status = HealthDown
+			result.Error = checkDone.err.Error()
+		}
+	case <-checkCtx.Done():
+		result.Status = HealthDown
+		result.Error = fmt.Sprintf("检查超时（%s）", timeout)
+	}
+	result.Duration = time.Since(begin).String()
+	return result
+}
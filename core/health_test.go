@@ -0,0 +1,37 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheckHealth(t *testing.T) {
+	RegisterHealthCheck("health.ok", func(ctx context.Context) (any, error) {
+		return map[string]int{"open": 1}, nil
+	})
+	RegisterHealthCheck("health.slow", func(ctx context.Context) (any, error) {
+		time.Sleep(time.Second)
+		return nil, nil
+	})
+	RegisterHealthCheck("health.fail", func(ctx context.Context) (any, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	begin := time.Now()
+	report := CheckHealth(context.Background(), 50*time.Millisecond)
+	if time.Second <= time.Since(begin) {
+		t.Fatal("超时的检查不应阻塞报告")
+	}
+	if HealthDown != report.Status || 3 != len(report.Checks) {
+		t.Fatalf("%+v", report)
+	}
+	statusMap := map[string]string{}
+	for _, result := range report.Checks {
+		statusMap[result.Name] = result.Status
+	}
+	if HealthUp != statusMap["health.ok"] || HealthDown != statusMap["health.slow"] || HealthDown != statusMap["health.fail"] {
+		t.Fatalf("%+v", report)
+	}
+}
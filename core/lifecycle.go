@@ -0,0 +1,141 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/mini-tiger/fast-api/dError"
+)
+
+// hookType 生命周期钩子
+type hookType struct {
+	name  string
+	start func() error
+	stop  func(ctx context.Context) error
+}
+
+var hookLock sync.Mutex
+var startHookList []*hookType
+var stopHookList []*hookType
+
+// OnStart 注册启动钩子，在组件启动后按注册顺序执行
+func OnStart(name string, fn func() error) {
+	hookLock.Lock()
+	defer hookLock.Unlock()
+	startHookList = append(startHookList, &hookType{name: name, start: fn})
+}
+
+// OnStop 注册退出钩子，在组件停止前按注册的逆序执行
+func OnStop(name string, fn func(ctx context.Context) error) {
+	hookLock.Lock()
+	defer hookLock.Unlock()
+	stopHookList = append(stopHookList, &hookType{name: name, stop: fn})
+}
+
+// Run 启动组件并执行启动钩子，然后阻塞直到收到 SIGINT/SIGTERM，最后按顺序退出
+func (a *AppType) Run() error {
+	if err := a.Start(); nil != err {
+		// 已启动的部分也要退出
+		_ = a.Shutdown()
+		return err
+	}
+
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signalChan)
+	sig := <-signalChan
+	fmt.Printf("收到信号 %s，开始退出\n", sig)
+
+	return a.Shutdown()
+}
+
+// Start 按初始化顺序启动组件，然后执行启动钩子
+func (a *AppType) Start() error {
+	Start()
+
+	a.lock.Lock()
+	componentList := append([]*ComponentType{}, a.initList...)
+	a.lock.Unlock()
+	for _, component := range componentList {
+		if nil == component.Start {
+			continue
+		}
+		if err := component.Start(); nil != err {
+			return dError.NewError(fmt.Sprintf("组件 %s 启动失败", component.Name), err)
+		}
+	}
+
+	hookLock.Lock()
+	hookList := append([]*hookType{}, startHookList...)
+	hookLock.Unlock()
+	for _, hook := range hookList {
+		if err := hook.start(); nil != err {
+			return dError.NewError(fmt.Sprintf("启动钩子 %s 执行失败", hook.name), err)
+		}
+	}
+	return nil
+}
+
+// Stopping 应用是否正在退出
+func (a *AppType) Stopping() bool {
+	return a.stopping.Load()
+}
+
+// Shutdown 逆序执行退出钩子，再逆序停止组件；每一步单独计算超时，错误汇总后返回
+func (a *AppType) Shutdown() error {
+	var errList []error
+	a.stopOnce.Do(func() {
+		a.stopping.Store(true)
+
+		hookLock.Lock()
+		hookList := append([]*hookType{}, stopHookList...)
+		hookLock.Unlock()
+		for i := len(hookList) - 1; i >= 0; i-- {
+			if err := a.runStopStep(hookList[i].name, hookList[i].stop); nil != err {
+				errList = append(errList, err)
+			}
+		}
+
+		a.lock.Lock()
+		componentList := append([]*ComponentType{}, a.initList...)
+		a.lock.Unlock()
+		for i := len(componentList) - 1; i >= 0; i-- {
+			component := componentList[i]
+			if nil == component.Stop {
+				continue
+			}
+			if err := a.runStopStep(component.Name, component.Stop); nil != err {
+				errList = append(errList, err)
+			}
+		}
+	})
+
+	if 0 < len(errList) {
+		return dError.NewError("应用退出时出错", errList...)
+	}
+	return nil
+}
+
+// runStopStep 执行单个退出步骤，超时后不再等待
+func (a *AppType) runStopStep(name string, fn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), a.options.StopTimeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(ctx)
+	}()
+	select {
+	case err := <-done:
+		if nil != err {
+			return fmt.Errorf("%s 退出失败: %w", name, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%s 退出超时（%s）", name, a.options.StopTimeout)
+	}
+}
@@ -0,0 +1,56 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestShutdownOrder(t *testing.T) {
+	useTempAppPath(t)
+	var stopOrder []string
+	newComponent := func(name string, order int) *ComponentType {
+		return &ComponentType{
+			Name:  name,
+			Order: order,
+			Stop: func(ctx context.Context) error {
+				stopOrder = append(stopOrder, name)
+				return nil
+			},
+		}
+	}
+	RegisterComponent(newComponent("stop.db", 1))
+	RegisterComponent(newComponent("stop.cron", 2))
+	OnStop("stop.hook", func(ctx context.Context) error {
+		stopOrder = append(stopOrder, "stop.hook")
+		return nil
+	})
+	// 超时的步骤不能阻塞后续步骤
+	RegisterComponent(&ComponentType{
+		Name:  "stop.slow",
+		Order: 3,
+		Stop: func(ctx context.Context) error {
+			<-ctx.Done()
+			time.Sleep(time.Second)
+			return nil
+		},
+	})
+
+	application, err := Bootstrap(OptionsType{
+		Components:  []string{"stop.db", "stop.cron", "stop.slow"},
+		StopTimeout: 50 * time.Millisecond,
+	})
+	if nil != err {
+		t.Fatal(err)
+	}
+	err = application.Shutdown()
+	if nil == err {
+		t.Fatal("超时的步骤应返回错误")
+	}
+	if !application.Stopping() {
+		t.Fatal("退出后 Stopping 应为 true")
+	}
+	if 3 != len(stopOrder) || "stop.hook" != stopOrder[0] || "stop.cron" != stopOrder[1] || "stop.db" != stopOrder[2] {
+		t.Fatalf("退出顺序错误: %v", stopOrder)
+	}
+}
@@ -0,0 +1,105 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/mini-tiger/fast-api/dError"
+)
+
+// Mode 项目运行环境 [dev, test, produce]，可通过 RegisterMode 扩展
+var Mode = Dev
+
+type ModeType string
+
+const (
+	Dev     ModeType = "dev"
+	Test    ModeType = "test"
+	Produce ModeType = "produce"
+)
+
+// ModeEnv 指定运行环境的环境变量
+const ModeEnv = "APP_MODE"
+
+// ModeFlag 指定运行环境的命令行参数，支持 --mode=produce 和 --mode produce
+const ModeFlag = "mode"
+
+// flagMode 命令行框架解析出的 --mode 参数，优先于 os.Args 中的值
+var flagMode string
+
+// SetFlagMode 设置命令行框架解析出的 --mode 参数
+func SetFlagMode(mode string) {
+	flagMode = mode
+}
+
+var modeLock sync.RWMutex
+var modeMap = map[ModeType]bool{
+	Dev:     true,
+	Test:    true,
+	Produce: true,
+}
+
+// RegisterMode 注册自定义运行环境，如 staging、pre
+func RegisterMode(modeList ...ModeType) {
+	modeLock.Lock()
+	defer modeLock.Unlock()
+	for _, mode := range modeList {
+		modeMap[mode] = true
+	}
+}
+
+// ModeExist 判断运行环境是否已注册
+func ModeExist(mode ModeType) bool {
+	modeLock.RLock()
+	defer modeLock.RUnlock()
+	return modeMap[mode]
+}
+
+// ResolveMode 解析运行环境并写入 Mode
+// 优先级：--mode 参数 > APP_MODE 环境变量 > 第一个命令行参数（仅当其为已注册环境时） > dev
+// 通过参数或环境变量显式指定了未注册的环境时返回错误，不会回退到 dev
+func ResolveMode() error {
+	modeStr, from := lookupMode(os.Args[1:])
+	if "" == modeStr {
+		Mode = Dev
+		return nil
+	}
+	if !ModeExist(ModeType(modeStr)) {
+		Mode = Dev
+		return dError.NewError(fmt.Sprintf("未知的运行环境 %s（来自%s），请先调用 core.RegisterMode 注册", modeStr, from))
+	}
+	Mode = ModeType(modeStr)
+	return nil
+}
+
+// lookupMode 查找显式指定的运行环境及其来源
+func lookupMode(args []string) (string, string) {
+	if "" != flagMode {
+		return flagMode, "参数 --" + ModeFlag
+	}
+	for i, arg := range args {
+		if "--" == arg {
+			break
+		}
+		name := strings.TrimLeft(arg, "-")
+		if name == arg {
+			continue
+		}
+		if value, ok := strings.CutPrefix(name, ModeFlag+"="); ok {
+			return value, "参数 --" + ModeFlag
+		}
+		if ModeFlag == name && i+1 < len(args) {
+			return args[i+1], "参数 --" + ModeFlag
+		}
+	}
+	if value := os.Getenv(ModeEnv); "" != value {
+		return value, "环境变量 " + ModeEnv
+	}
+	// 兼容旧用法：第一个参数为环境名称，其他取值视为业务参数忽略
+	if 0 < len(args) && ModeExist(ModeType(args[0])) {
+		return args[0], "第一个命令行参数"
+	}
+	return "", ""
+}
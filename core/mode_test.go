@@ -0,0 +1,49 @@
+package core
+
+import (
+	"os"
+	"testing"
+)
+
+func TestResolveMode(t *testing.T) {
+	oldArgs := os.Args
+	defer func() {
+		os.Args = oldArgs
+		Mode = Dev
+	}()
+	RegisterMode("staging")
+
+	caseList := []struct {
+		args    []string
+		env     string
+		mode    ModeType
+		wantErr bool
+	}{
+		{args: nil, mode: Dev},
+		{args: []string{"produce"}, mode: Produce},
+		{args: []string{"serve"}, mode: Dev},
+		{args: []string{"serve", "--mode=staging"}, mode: "staging"},
+		{args: []string{"serve", "--mode", "test"}, env: "produce", mode: Test},
+		{args: []string{"serve"}, env: "staging", mode: "staging"},
+		{args: []string{"serve"}, env: "prodcue", wantErr: true},
+		{args: []string{"--mode=unknown"}, wantErr: true},
+	}
+	for _, c := range caseList {
+		os.Args = append([]string{"app"}, c.args...)
+		t.Setenv(ModeEnv, c.env)
+		err := ResolveMode()
+		if c.wantErr {
+			if nil == err {
+				t.Errorf("%v %s: 应返回错误", c.args, c.env)
+			}
+			continue
+		}
+		if nil != err {
+			t.Errorf("%v %s: %v", c.args, c.env, err)
+			continue
+		}
+		if c.mode != Mode {
+			t.Errorf("%v %s: 期望 %s 实际 %s", c.args, c.env, c.mode, Mode)
+		}
+	}
+}
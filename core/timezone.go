@@ -0,0 +1,32 @@
+package core
+
+import (
+	"fmt"
+	"time"
+	// 内置时区数据，容器中没有 /usr/share/zoneinfo 时也能加载时区
+	_ "time/tzdata"
+
+	"github.com/mini-tiger/fast-api/dError"
+)
+
+// DefaultTimezone 未配置 [core] timezone 时使用的时区
+const DefaultTimezone = "Asia/Shanghai"
+
+// LoadLocation 加载时区，名称无效时返回明确的错误
+func LoadLocation(name string) (*time.Location, error) {
+	location, err := time.LoadLocation(name)
+	if nil != err {
+		return nil, dError.NewError(fmt.Sprintf("时区 %s 无效", name), err)
+	}
+	return location, nil
+}
+
+// SetTimezone 设置全局时区 time.Local，失败时 time.Local 保持不变
+func SetTimezone(name string) error {
+	location, err := LoadLocation(name)
+	if nil != err {
+		return err
+	}
+	time.Local = location
+	return nil
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+// 构建信息，编译时通过 ldflags 注入，如：
+// go build -ldflags "-X github.com/mini-tiger/fast-api/core.Version=v1.2.0 -X github.com/mini-tiger/fast-api/core.GitCommit=$(git rev-parse HEAD) -X github.com/mini-tiger/fast-api/core.BuildTime=$(date +%FT%T%z)"
+// 未注入时从 debug.ReadBuildInfo 读取
+var (
+	Version   = ""
+	GitCommit = ""
+	BuildTime = ""
+)
+
+// BuildInfoType 构建信息
+type BuildInfoType struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"git_commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+var buildInfo BuildInfoType
+var buildInfoOnce sync.Once
+
+// GetBuildInfo 获取构建信息，ldflags 注入的值优先
+func GetBuildInfo() BuildInfoType {
+	buildInfoOnce.Do(func() {
+		buildInfo = BuildInfoType{
+			Version:   Version,
+			GitCommit: GitCommit,
+			BuildTime: BuildTime,
+			GoVersion: runtime.Version(),
+		}
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+		if "" == buildInfo.Version && "(devel)" != info.Main.Version {
+			buildInfo.Version = info.Main.Version
+		}
+		modified := false
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if "" == buildInfo.GitCommit {
+					buildInfo.GitCommit = setting.Value
+				}
+			case "vcs.time":
+				if "" == buildInfo.BuildTime {
+					buildInfo.BuildTime = setting.Value
+				}
+			case "vcs.modified":
+				modified = "true" == setting.Value
+			}
+		}
+		// 未提交的改动构建时标记 dirty
+		if modified && "" == GitCommit && "" != buildInfo.GitCommit {
+			buildInfo.GitCommit += "-dirty"
+		}
+	})
+	return buildInfo
+}
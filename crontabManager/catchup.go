@@ -0,0 +1,261 @@
+package crontabManager
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mini-tiger/fast-api/cache"
+	"github.com/mini-tiger/fast-api/config"
+	"github.com/mini-tiger/fast-api/dError"
+	"github.com/mini-tiger/fast-api/dLogger"
+	"github.com/mini-tiger/fast-api/dbManager"
+	"github.com/redis/go-redis/v9"
+	"github.com/robfig/cron/v3"
+)
+
+// 停机期间错过的执行在启动后的处理方式
+const (
+	// CatchUpNone 不补执行，默认值
+	CatchUpNone CatchUpType = "none"
+	// CatchUpOnce 只补执行一次，调度时间为最近一次错过的时间
+	CatchUpOnce CatchUpType = "once"
+	// CatchUpAll 按调度时间从早到晚逐次补执行，最多补执行最近的 catchUpLimit 次，失败时停止
+	CatchUpAll CatchUpType = "all"
+)
+
+type CatchUpType string
+
+// defaultCatchUpLimit catchUp = all 且未设置次数时最多补执行的次数
+const defaultCatchUpLimit = 10
+
+// maxMissedCount 计算错过的执行时最多检查的次数，避免周期很短的任务停机很久后计算过久
+const maxMissedCount = 100000
+
+// successField 最近一次成功的调度时间在 Redis 哈希 cron:<serverName>:<mode>:last:<name> 中的字段
+const successField = "success"
+
+// scheduledKeyType 调度时间在 ctx 中的键
+type scheduledKeyType struct{}
+
+// ScheduledTime 本次执行对应的调度时间，补执行时为错过的时间，如结算任务据此确定结算哪一天；
+// 手动执行时为当前时间；ctx 须为任务函数收到的 ctx
+func ScheduledTime(ctx context.Context) time.Time {
+	if scheduled, ok := ctx.Value(scheduledKeyType{}).(time.Time); ok {
+		return scheduled
+	}
+	return now()
+}
+
+// SetCatchUp 停机期间错过的执行在启动后的处理方式，limit 为 CatchUpAll 时最多补执行的次数，为 0 时为 10；
+// 可被 [crontab] <name>.catchUp、<name>.catchUpLimit 覆盖。
+// 最近一次成功的调度时间配置了 [redis] 时保存在 Redis 中，否则从 cron_run 表的执行记录中读取
+func (j *JobType) SetCatchUp(catchUp CatchUpType, limit int) *JobType {
+	jobLock.Lock()
+	defer jobLock.Unlock()
+	j.defaultCatchUp, j.defaultCatchUpLimit = catchUp, limit
+	j.catchUp, j.catchUpLimit = catchUp, limit
+	return j
+}
+
+// configureCatchUp 按 [crontab] 配置更新补执行方式和次数，调用方需持有 jobLock
+func (j *JobType) configureCatchUp(cronConfig *ConfigType) {
+	j.catchUp, j.catchUpLimit = j.defaultCatchUp, j.defaultCatchUpLimit
+	if value, ok := cronConfig.Jobs[j.name+".catchUp"]; ok {
+		j.catchUp = CatchUpType(value)
+	}
+	if value, ok := cronConfig.Jobs[j.name+".catchUpLimit"]; ok {
+		j.catchUpLimit, _ = strconv.Atoi(value)
+	}
+}
+
+// validateCatchUp 校验 <name>.catchUp、<name>.catchUpLimit 配置
+func validateCatchUp(key, value string) error {
+	switch {
+	case strings.HasSuffix(key, ".catchUp"):
+		switch CatchUpType(value) {
+		case CatchUpNone, CatchUpOnce, CatchUpAll:
+		default:
+			return fmt.Errorf("配置 [crontab] %s = %s 错误: 可选值 none、once、all", key, value)
+		}
+	case strings.HasSuffix(key, ".catchUpLimit"):
+		if limit, err := strconv.Atoi(value); nil != err || 0 > limit {
+			return fmt.Errorf("配置 [crontab] %s = %s 错误: 应为不小于 0 的整数", key, value)
+		}
+	}
+	return nil
+}
+
+// catchUpOptions 补执行方式和次数
+func (j *JobType) catchUpOptions() (CatchUpType, int) {
+	jobLock.Lock()
+	defer jobLock.Unlock()
+	catchUp, limit := j.catchUp, j.catchUpLimit
+	if "" == catchUp {
+		catchUp = CatchUpNone
+	}
+	if 0 >= limit {
+		limit = defaultCatchUpLimit
+	}
+	return catchUp, limit
+}
+
+// lastSuccess 最近一次成功的调度时间，没有记录时返回零值
+func (j *JobType) lastSuccess() (time.Time, error) {
+	location := GetInstance().Location()
+	if config.HasSection("redis") {
+		key, err := j.redisKey("last")
+		if nil != err {
+			return time.Time{}, err
+		}
+		// 字段不存在时 HGet 返回错误，首次启动时应视为没有记录
+		valueMap, err := cache.HGetAll(key)
+		if nil != err || "" == valueMap[successField] {
+			return time.Time{}, err
+		}
+		value := valueMap[successField]
+		unix, err := strconv.ParseInt(value, 10, 64)
+		if nil != err {
+			return time.Time{}, err
+		}
+		return time.Unix(unix, 0).In(location), nil
+	}
+	if enabled, _ := historyConfig(); !enabled {
+		return time.Time{}, dError.NewError("补执行需要配置 [redis] 或开启 [crontab] history")
+	}
+	if err := dbManager.Init(); nil != err {
+		return time.Time{}, err
+	}
+	var value string
+	err := dbManager.GetInstance().Model(&RunModelType{}).
+		Select("COALESCE(MAX(scheduled_time), '')").
+		Where("name = ? AND status = ? AND scheduled_time <> ''", j.name, RunStatusSuccess).
+		Scan(&value).Error
+	if nil != err || "" == value {
+		return time.Time{}, err
+	}
+	return time.ParseInLocation(timeLayout, value, location)
+}
+
+// recordSuccessScript 新的调度时间晚于已记录的时间时才更新，补执行与周期调度同时成功时不会回退
+var recordSuccessScript = redis.NewScript(`
+local current = tonumber(redis.call("HGET", KEYS[1], ARGV[1]) or "0")
+if tonumber(ARGV[2]) <= current then
+	return 0
+end
+redis.call("HSET", KEYS[1], ARGV[1], ARGV[2])
+return 1
+`)
+
+// recordSuccess 记录最近一次成功的调度时间，只向后推进；未配置 [redis] 时由 cron_run 表的执行记录提供
+func (j *JobType) recordSuccess(scheduled time.Time) {
+	if catchUp, _ := j.catchUpOptions(); CatchUpNone == catchUp || !config.HasSection("redis") {
+		return
+	}
+	key, err := j.redisKey("last")
+	if nil == err {
+		err = recordSuccessScript.Run(context.Background(), cache.GetInstance(), []string{key}, successField, scheduled.Unix()).Err()
+	}
+	if nil != err {
+		dLogger.Write(dLogger.LeverError, "cron", fmt.Sprintf("定时任务 %s 记录成功时间失败: %s", j.name, err.Error()))
+	}
+}
+
+// missedTimes 在 last 之后、until 及之前错过的调度时间，从早到晚最多保留最近的 limit 个，并返回错过的总数
+func missedTimes(schedule cron.Schedule, last, until time.Time, limit int) ([]time.Time, int) {
+	var missedList []time.Time
+	count := 0
+	for next := schedule.Next(last); !next.IsZero() && !next.After(until) && count < maxMissedCount; next = schedule.Next(next) {
+		count++
+		missedList = append(missedList, next)
+		if limit < len(missedList) {
+			missedList = missedList[1:]
+		}
+	}
+	return missedList, count
+}
+
+// catchUpAll 在后台为全部需要补执行的任务补执行，until 为调度开始的时间，之后的调度由调度器执行
+func catchUpAll(until time.Time) {
+	jobLock.Lock()
+	var jobList []*JobType
+	for _, job := range jobMap {
+		if job.enabled && "" != job.catchUp && CatchUpNone != job.catchUp {
+			jobList = append(jobList, job)
+		}
+	}
+	jobLock.Unlock()
+	for _, job := range jobList {
+		_ = startBackground(func() {
+			defer recoverPanic(job.name + " 补执行")
+			job.runMissed(until)
+		})
+	}
+}
+
+// runMissed 补执行 until 及之前错过的执行；没有成功记录时记录 until 作为起点，之后停机错过的执行才会补执行
+func (j *JobType) runMissed(until time.Time) {
+	catchUp, limit := j.catchUpOptions()
+	if CatchUpNone == catchUp || j.isPaused() {
+		return
+	}
+	jobLock.Lock()
+	spec := j.spec
+	jobLock.Unlock()
+	schedule, err := cron.ParseStandard(spec)
+	if nil != err {
+		return
+	}
+	last, err := j.lastSuccess()
+	if nil != err {
+		dLogger.Write(dLogger.LeverError, "cron", fmt.Sprintf("定时任务 %s 读取最近一次成功时间失败，无法补执行: %s", j.name, err.Error()))
+		return
+	}
+	if last.IsZero() {
+		j.recordSuccess(until)
+		return
+	}
+	if CatchUpOnce == catchUp {
+		limit = 1
+	}
+	missedList, count := missedTimes(schedule, last, until, limit)
+	if 0 == count {
+		return
+	}
+	if CatchUpAll == catchUp && len(missedList) < count {
+		dLogger.Write(dLogger.LeverWaning, "cron", fmt.Sprintf("定时任务 %s 错过 %d 次执行，只补执行最近的 %d 次", j.name, count, len(missedList)))
+	}
+	j.runBatch(missedList)
+}
+
+// runBatch 从早到晚逐次补执行 missedList，失败时停止，返回跳过的调度时间；
+// 单实例任务与周期调度一样按每个调度时间获取锁，多个实例同时补执行时每个调度时间只执行一次
+func (j *JobType) runBatch(missedList []time.Time) []time.Time {
+	var skipList []time.Time
+	skip := func(scheduledList []time.Time, reason string) {
+		for _, scheduled := range scheduledList {
+			dLogger.Write(dLogger.LeverWaning, "cron", fmt.Sprintf("定时任务 %s %s，跳过补执行 %s", j.name, reason, scheduled.Format(timeLayout)))
+		}
+		skipList = append(skipList, scheduledList...)
+	}
+	for i, scheduled := range missedList {
+		// 应用退出时停止补执行
+		if nil != jobContext().Err() || isStopping() {
+			skip(missedList[i:], "应用正在退出")
+			return skipList
+		}
+		dLogger.Write(dLogger.LeverInfo, "cron", fmt.Sprintf("定时任务 %s 补执行 %s", j.name, scheduled.Format(timeLayout)))
+		ran, err := j.tryFire(scheduled)
+		if !ran {
+			skip(missedList[i:i+1], "本次未执行")
+			continue
+		}
+		if nil != err {
+			skip(missedList[i+1:], "补执行失败")
+			return skipList
+		}
+	}
+	return skipList
+}
@@ -0,0 +1,101 @@
+package crontabManager
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/mini-tiger/fast-api/command"
+	"github.com/mini-tiger/fast-api/core"
+	"github.com/mini-tiger/fast-api/dError"
+)
+
+func init() {
+	command.Register("cron list", &command.CommandType{
+		Usage:      "列出已注册的定时任务",
+		Components: []string{core.ComponentCron},
+		Run: func(c *command.ContextType) error {
+			if err := reschedule(); nil != err {
+				return err
+			}
+			for _, status := range List() {
+				next := "已停用"
+				if status.Paused {
+					next = "已暂停"
+				} else if status.Enabled {
+					next = "下次执行 " + status.Next.Format("2006-01-02 15:04:05")
+				}
+				if "" != status.LastHost {
+					next += "，上次执行实例 " + status.LastHost
+				}
+				_, _ = fmt.Fprintf(c.Out, "%-24s %-20s %s\n", status.Name, status.Spec, next)
+			}
+			return nil
+		},
+	})
+	command.Register("cron history", &command.CommandType{
+		Usage:      "查看定时任务最近的执行记录",
+		ArgsUsage:  "[job]",
+		Components: []string{core.ComponentCron, core.ComponentMysql},
+		Flags: func(flagSet *flag.FlagSet) {
+			flagSet.Int("limit", 20, "显示的记录数量")
+		},
+		Run: func(c *command.ContextType) error {
+			runList, err := Runs(c.Arg(0), c.Int("limit"))
+			if nil != err {
+				return err
+			}
+			for _, run := range runList {
+				result := run.Status
+				if "" != run.Error {
+					result += " " + run.Error
+				} else if "" != run.Summary {
+					result += " " + run.Summary
+				}
+				_, _ = fmt.Fprintf(c.Out, "%-24s %s %6dms %-16s %s\n", run.Name, run.StartTime, run.Duration, run.Host, result)
+			}
+			return nil
+		},
+	})
+	command.Register("cron trigger", &command.CommandType{
+		Usage:      "按调度的方式执行一次定时任务，遵循处理方式、超时和重试设置",
+		ArgsUsage:  "<job>",
+		Components: []string{core.ComponentCron},
+		Run: func(c *command.ContextType) error {
+			job := Get(c.Arg(0))
+			if nil == job {
+				return dError.NewError("请指定存在的任务名称，可通过 cron list 查看")
+			}
+			return job.fire(time.Time{})
+		},
+	})
+	command.Register("cron pause", &command.CommandType{
+		Usage:      "暂停定时任务，对全部实例生效",
+		ArgsUsage:  "<job>",
+		Components: []string{core.ComponentCron, core.ComponentRedis},
+		Run: func(c *command.ContextType) error {
+			return Pause(c.Arg(0))
+		},
+	})
+	command.Register("cron resume", &command.CommandType{
+		Usage:      "恢复已暂停的定时任务",
+		ArgsUsage:  "<job>",
+		Components: []string{core.ComponentCron, core.ComponentRedis},
+		Run: func(c *command.ContextType) error {
+			return Resume(c.Arg(0))
+		},
+	})
+	command.Register("cron run", &command.CommandType{
+		Usage:      "立即执行一次定时任务",
+		ArgsUsage:  "<job>",
+		Components: []string{core.ComponentCron},
+		Run: func(c *command.ContextType) error {
+			name := c.Arg(0)
+			if "" == name {
+				return dError.NewError("请指定任务名称，可通过 cron list 查看")
+			}
+			return Run(context.Background(), name)
+		},
+	})
+}
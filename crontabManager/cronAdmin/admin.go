@@ -0,0 +1,61 @@
+// Package cronAdmin 定时任务的管理路由，需要时引入即注册，只运行定时任务的程序无需引入 HTTP 组件：
+//
+//	import _ "github.com/mini-tiger/fast-api/crontabManager/cronAdmin"
+package cronAdmin
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/mini-tiger/fast-api/crontabManager"
+	"github.com/mini-tiger/fast-api/dError"
+	"github.com/mini-tiger/fast-api/server"
+)
+
+// AdminPath 定时任务的管理路由，位于 server.AdminPrefix 下：
+//
+//	GET  /admin/cron                  全部任务的状态
+//	POST /admin/cron/{name}/trigger   立即执行一次
+//	POST /admin/cron/{name}/pause     暂停
+//	POST /admin/cron/{name}/resume    恢复
+const AdminPath = "/cron"
+
+func init() {
+	admin := server.Admin()
+	admin.GET(AdminPath, AdminList)
+	admin.POST(AdminPath+"/{name}/trigger", AdminTrigger)
+	admin.POST(AdminPath+"/{name}/pause", AdminPause)
+	admin.POST(AdminPath+"/{name}/resume", AdminResume)
+}
+
+// AdminList 返回全部任务的状态
+func AdminList(c *server.ContextType) error {
+	return c.Success(crontabManager.List())
+}
+
+// AdminTrigger 立即在后台执行一次任务
+func AdminTrigger(c *server.ContextType) error {
+	return adminAction(c, crontabManager.Trigger)
+}
+
+// AdminPause 暂停任务
+func AdminPause(c *server.ContextType) error {
+	return adminAction(c, crontabManager.Pause)
+}
+
+// AdminResume 恢复任务
+func AdminResume(c *server.ContextType) error {
+	return adminAction(c, crontabManager.Resume)
+}
+
+// adminAction 对路径中的任务执行操作，任务不存在时返回 404
+func adminAction(c *server.ContextType, action func(name string) error) error {
+	name := c.Param("name")
+	if nil == crontabManager.Get(name) {
+		return dError.NewError(fmt.Sprintf("定时任务 %s 不存在", name)).SetHttpStatus(http.StatusNotFound)
+	}
+	if err := action(name); nil != err {
+		return err
+	}
+	return c.Success(nil)
+}
@@ -0,0 +1,20 @@
+package cronAdmin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mini-tiger/fast-api/server"
+)
+
+func TestAdminAction(t *testing.T) {
+	r := server.NewRouter()
+	r.POST(AdminPath+"/{name}/pause", AdminPause)
+	req := httptest.NewRequest(http.MethodPost, AdminPath+"/missing/pause", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if http.StatusNotFound != w.Code {
+		t.Fatal("任务不存在时应返回 404", w.Code)
+	}
+}
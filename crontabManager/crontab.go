@@ -1,13 +1,297 @@
 package crontabManager
 
-import "github.com/robfig/cron/v3"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mini-tiger/fast-api/config"
+	"github.com/mini-tiger/fast-api/core"
+	"github.com/mini-tiger/fast-api/dError"
+	"github.com/mini-tiger/fast-api/dLogger"
+	"github.com/robfig/cron/v3"
+)
 
 var server *cron.Cron
+var lock sync.Mutex
+
+// jobCtx 传给任务函数的 ctx，应用退出超时时取消
+var jobCtx = context.Background()
+var cancelJobs context.CancelFunc = func() {}
+
+// running 调度器是否在运行
+var running atomic.Bool
+
+var backgroundLock sync.Mutex
+
+// backgroundGroup 调度器之外在后台执行的任务，如补执行、手动执行及超时后等待任务函数返回，Stop 时一并等待
+var backgroundGroup sync.WaitGroup
+
+// stopping 是否正在停止，停止期间不再开始新的后台执行
+var stopping bool
 
 func init() {
-	server = cron.New()
+	core.RegisterComponent(&core.ComponentType{
+		Name:    core.ComponentCron,
+		Order:   60,
+		Depends: []string{core.ComponentConfig},
+		Validate: func() error {
+			_, err := GetConfig()
+			return err
+		},
+		Init:  Init,
+		Start: Start,
+		Stop:  Stop,
+	})
+	config.OnChange("crontab", func() {
+		if err := reschedule(); nil != err {
+			dLogger.Write(dLogger.LeverError, "cron", err)
+		}
+	})
+}
+
+// Init 创建定时任务调度器，已创建时直接返回
+func Init() error {
+	lock.Lock()
+	defer lock.Unlock()
+	if nil != server {
+		return nil
+	}
+	location, err := loadLocation()
+	if nil != err {
+		return err
+	}
+	// 任务的 panic 不能导致进程退出
+	server = cron.New(cron.WithLocation(location), cron.WithChain(recoverWrapper))
+	jobCtx, cancelJobs = context.WithCancel(context.Background())
+	core.RegisterHealthCheck(core.ComponentCron, healthCheck)
+	// 热加载时新配置同样需要通过校验，如任务的执行周期
+	config.Register("crontab", &ConfigType{})
+	return nil
+}
+
+// reschedule 按 [crontab] 配置调度全部已注册的任务，执行周期和是否启用有变化的任务重新调度
+func reschedule() error {
+	lock.Lock()
+	created := nil != server
+	lock.Unlock()
+	if !created {
+		return nil
+	}
+	cronConfig := &ConfigType{}
+	// 没有配置文件时使用注册时的默认周期
+	if nil == config.Load() {
+		var err error
+		if cronConfig, err = GetConfig(); nil != err {
+			return err
+		}
+	}
+	return scheduleAll(cronConfig)
+}
+
+// jobContext 传给任务函数的 ctx
+func jobContext() context.Context {
+	lock.Lock()
+	defer lock.Unlock()
+	return jobCtx
+}
+
+// healthCheck 返回调度器运行状态及任务数量，Start 之后未运行视为异常
+func healthCheck(ctx context.Context) (any, error) {
+	detail := map[string]any{
+		"running": running.Load(),
+		"entries": len(GetInstance().Entries()),
+	}
+	if !running.Load() {
+		return detail, errors.New("定时任务调度器未运行")
+	}
+	// 超时后不响应 ctx 的任务一直占用执行名额，之后的调度全部跳过，需要重启实例
+	if stuckMap := stuckJobs(); 0 < len(stuckMap) {
+		detail["stuck"] = stuckMap
+		return detail, errors.New("定时任务超时后仍未结束")
+	}
+	return detail, nil
+}
+
+// stuckJobs 超时后任务函数仍未返回的任务及数量
+func stuckJobs() map[string]int {
+	jobLock.Lock()
+	defer jobLock.Unlock()
+	stuckMap := map[string]int{}
+	for name, job := range jobMap {
+		if count := job.stuckCount.Load(); 0 < count {
+			stuckMap[name] = int(count)
+		}
+	}
+	return stuckMap
+}
+
+// ConfigType [crontab] 配置
+type ConfigType struct {
+	// Timezone 调度使用的时区，为空时使用全局时区，修改后需要重启生效
+	Timezone string `ini:"timezone"`
+	// History 是否在 cron_run 表中记录每次执行，开启前需执行 migrate up 创建 cron_run 表
+	History bool `ini:"history" default:"false"`
+	// HistoryKeep 执行记录的保留时长，为 0 时不清理
+	HistoryKeep time.Duration `ini:"historyKeep" default:"720h" range:"0s,"`
+	// Jobs 分组中的全部配置项，<name> 为任务的执行周期，<name>.enabled 为是否启用
+	Jobs map[string]string `ini:"*"`
 }
 
+// Validate 校验时区、任务选项及已注册任务的执行周期
+func (c *ConfigType) Validate() error {
+	var errList []error
+	if "" != c.Timezone {
+		if _, err := core.LoadLocation(c.Timezone); nil != err {
+			errList = append(errList, dError.NewError("配置 [crontab] timezone 错误", err))
+		}
+	}
+	for key, value := range c.Jobs {
+		if name, ok := strings.CutSuffix(key, ".enabled"); ok {
+			if _, err := strconv.ParseBool(value); nil != err {
+				errList = append(errList, fmt.Errorf("配置 [crontab] %s.enabled = %s 错误: %w", name, value, err))
+			}
+		}
+		if err := validateRetry(key, value); nil != err {
+			errList = append(errList, err)
+		}
+		if err := validateOverlap(key, value); nil != err {
+			errList = append(errList, err)
+		}
+		if err := validateCatchUp(key, value); nil != err {
+			errList = append(errList, err)
+		}
+	}
+	jobLock.Lock()
+	for name, job := range jobMap {
+		spec := job.defaultSpec
+		if value := strings.TrimSpace(c.Jobs[name]); "" != value {
+			spec = value
+		}
+		if _, err := cron.ParseStandard(spec); nil != err {
+			errList = append(errList, fmt.Errorf("定时任务 %s 执行周期 %s 错误: %w", name, spec, err))
+		}
+		if job.singleton && isEvery(spec) {
+			errList = append(errList, fmt.Errorf("单实例任务 %s 的执行周期 %s 不能使用 @every", name, spec))
+		}
+	}
+	jobLock.Unlock()
+	if 0 < len(errList) {
+		return dError.NewError("配置 [crontab] 错误", errList...)
+	}
+	return nil
+}
+
+// GetConfig 读取并校验 [crontab] 配置
+func GetConfig() (*ConfigType, error) {
+	cronConfig := &ConfigType{}
+	return cronConfig, config.Bind("crontab", cronConfig)
+}
+
+// loadLocation 调度使用的时区，[crontab] timezone 未配置或没有配置文件时使用全局时区
+func loadLocation() (*time.Location, error) {
+	if err := config.Load(); nil != err {
+		return time.Local, nil
+	}
+	cronConfig, err := GetConfig()
+	if nil != err {
+		return nil, err
+	}
+	if "" == cronConfig.Timezone {
+		return time.Local, nil
+	}
+	return core.LoadLocation(cronConfig.Timezone)
+}
+
+// now 调度器时区的当前时间
+func now() time.Time {
+	return time.Now().In(GetInstance().Location())
+}
+
+// GetInstance 获取调度器，未创建时自动创建，时区配置错误会 panic
 func GetInstance() *cron.Cron {
+	if err := Init(); nil != err {
+		panic(err)
+	}
 	return server
 }
+
+// Start 调度已注册的任务并开始调度，停机期间错过的执行按补执行方式在后台补执行
+func Start() error {
+	if err := Init(); nil != err {
+		return err
+	}
+	if err := reschedule(); nil != err {
+		return err
+	}
+	// 调度器从启动时开始计算下一次执行，此前错过的执行由补执行处理
+	until := now()
+	GetInstance().Start()
+	running.Store(true)
+	catchUpAll(until)
+	startPrune()
+	return nil
+}
+
+// Stop 停止调度，并等待正在执行的任务及后台的补执行、手动执行结束；ctx 超时时取消任务的 ctx
+func Stop(ctx context.Context) error {
+	backgroundLock.Lock()
+	stopping = true
+	backgroundLock.Unlock()
+	defer func() {
+		backgroundLock.Lock()
+		stopping = false
+		backgroundLock.Unlock()
+	}()
+	stopCtx := GetInstance().Stop()
+	running.Store(false)
+	stopPrune()
+	done := make(chan struct{})
+	go func() {
+		// 调度器中的任务结束后不会再登记后台执行，此时才能等待
+		<-stopCtx.Done()
+		backgroundGroup.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		lock.Lock()
+		cancelJobs()
+		lock.Unlock()
+		return ctx.Err()
+	}
+}
+
+// goBackground 在后台执行 fn，Stop 时等待其结束
+func goBackground(fn func()) {
+	backgroundGroup.Add(1)
+	go func() {
+		defer backgroundGroup.Done()
+		fn()
+	}()
+}
+
+// startBackground 同 goBackground，正在停止时不再执行并返回错误
+func startBackground(fn func()) error {
+	backgroundLock.Lock()
+	defer backgroundLock.Unlock()
+	if stopping {
+		return dError.NewError("定时任务正在停止")
+	}
+	goBackground(fn)
+	return nil
+}
+
+// isStopping 是否正在停止
+func isStopping() bool {
+	backgroundLock.Lock()
+	defer backgroundLock.Unlock()
+	return stopping
+}
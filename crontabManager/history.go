@@ -0,0 +1,224 @@
+package crontabManager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mini-tiger/fast-api/dLogger"
+	"github.com/mini-tiger/fast-api/dbManager"
+	"gorm.io/gorm"
+)
+
+// 执行状态
+const (
+	RunStatusRunning = "running"
+	RunStatusSuccess = "success"
+	RunStatusFailed  = "failed"
+	RunStatusTimeout = "timeout"
+)
+
+// summaryMaxLength 执行摘要保留的最大字符数
+const summaryMaxLength = 1000
+
+// timeLayout 执行记录中的时间格式，按字符串比较即按时间先后
+const timeLayout = "2006-01-02 15:04:05"
+
+// RunModelType 任务的执行记录，[crontab] history = true 时记录
+type RunModelType struct {
+	Id        int    `gorm:"primaryKey" json:"id"`
+	Name      string `gorm:"size:128;index:idx_cron_run_name_start" json:"name"`
+	Host      string `gorm:"size:128" json:"host"`
+	StartTime string `gorm:"size:32;index:idx_cron_run_name_start" json:"startTime"`
+	EndTime   string `gorm:"size:32" json:"endTime"`
+	// ScheduledTime 本次对应的调度时间，补执行时为错过的时间，手动执行时为空
+	ScheduledTime string `gorm:"size:32" json:"scheduledTime"`
+	// Duration 执行耗时，单位毫秒
+	Duration int64  `json:"duration"`
+	Status   string `gorm:"size:16" json:"status"`
+	Error    string `gorm:"type:text" json:"error"`
+	Summary  string `gorm:"size:1024" json:"summary"`
+}
+
+func (m *RunModelType) TableName() string {
+	return "cron_run"
+}
+
+func init() {
+	dbManager.RegisterMigration(&dbManager.MigrationType{
+		Version: "20261017020000",
+		Name:    "创建定时任务执行记录 cron_run 表",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&RunModelType{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&RunModelType{})
+		},
+	})
+	// 补执行从最近一次成功的调度时间开始计算
+	dbManager.RegisterMigration(&dbManager.MigrationType{
+		Version: "20261017030000",
+		Name:    "cron_run 表增加 scheduled_time 字段",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&RunModelType{}, "ScheduledTime") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&RunModelType{}, "ScheduledTime")
+		},
+		Down: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&RunModelType{}, "ScheduledTime") {
+				return nil
+			}
+			return tx.Migrator().DropColumn(&RunModelType{}, "ScheduledTime")
+		},
+	})
+}
+
+// runKeyType 执行记录在 ctx 中的键
+type runKeyType struct{}
+
+// SetSummary 设置本次执行的摘要，如处理的数量，随执行记录保存；ctx 须为任务函数收到的 ctx
+func SetSummary(ctx context.Context, summary string) {
+	if run, ok := ctx.Value(runKeyType{}).(*RunModelType); ok {
+		if runes := []rune(summary); summaryMaxLength < len(runes) {
+			summary = string(runes[:summaryMaxLength])
+		}
+		run.Summary = summary
+	}
+}
+
+// historyConfig 是否记录执行记录及保留时长，读取配置失败时不记录
+func historyConfig() (bool, time.Duration) {
+	cronConfig, err := GetConfig()
+	if nil != err {
+		return false, 0
+	}
+	return cronConfig.History, cronConfig.HistoryKeep
+}
+
+// startRun 写入执行中的记录，不记录时返回 nil；写入失败只记录错误日志，不影响任务执行
+func (j *JobType) startRun(ctx context.Context, start time.Time) *RunModelType {
+	if enabled, _ := historyConfig(); !enabled {
+		return nil
+	}
+	run := &RunModelType{
+		Name:      j.name,
+		Host:      hostName,
+		StartTime: start.Format(timeLayout),
+		Status:    RunStatusRunning,
+	}
+	if scheduled, ok := ctx.Value(scheduledKeyType{}).(time.Time); ok {
+		run.ScheduledTime = scheduled.In(start.Location()).Format(timeLayout)
+	}
+	if err := dbManager.Init(); nil != err {
+		dLogger.Write(dLogger.LeverError, "cron", fmt.Sprintf("定时任务 %s 写入执行记录失败: %s", j.name, err.Error()))
+		return nil
+	}
+	if err := dbManager.GetInstance().Create(run).Error; nil != err {
+		dLogger.Write(dLogger.LeverError, "cron", fmt.Sprintf("定时任务 %s 写入执行记录失败: %s", j.name, err.Error()))
+		return nil
+	}
+	return run
+}
+
+// finishRun 更新执行结果
+func (j *JobType) finishRun(run *RunModelType, start time.Time, runErr error) {
+	end := now()
+	run.EndTime = end.Format(timeLayout)
+	run.Duration = end.Sub(start).Milliseconds()
+	run.Status = RunStatusSuccess
+	if nil != runErr {
+		run.Status = RunStatusFailed
+		if errors.Is(runErr, context.DeadlineExceeded) {
+			run.Status = RunStatusTimeout
+		}
+		run.Error = runErr.Error()
+	}
+	db := dbManager.GetInstance()
+	if err := db.Save(run).Error; nil != err {
+		dLogger.Write(dLogger.LeverError, "cron", fmt.Sprintf("定时任务 %s 更新执行记录失败: %s", j.name, err.Error()))
+	}
+}
+
+// pruneInterval 清理超过保留时长的执行记录的间隔
+const pruneInterval = time.Hour
+
+var pruneLock sync.Mutex
+
+// stopPruneChan 关闭时停止定时清理，未开始时为 nil
+var stopPruneChan chan struct{}
+
+// startPrune 开始定时清理超过 [crontab] historyKeep 的执行记录，调度开始后调用
+func startPrune() {
+	pruneLock.Lock()
+	defer pruneLock.Unlock()
+	if nil != stopPruneChan {
+		return
+	}
+	stopChan := make(chan struct{})
+	stopPruneChan = stopChan
+	go func() {
+		ticker := time.NewTicker(pruneInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopChan:
+				return
+			case <-ticker.C:
+				pruneHistory()
+			}
+		}
+	}()
+}
+
+// stopPrune 停止定时清理
+func stopPrune() {
+	pruneLock.Lock()
+	defer pruneLock.Unlock()
+	if nil != stopPruneChan {
+		close(stopPruneChan)
+		stopPruneChan = nil
+	}
+}
+
+// pruneHistory 删除全部任务超过保留时长的执行记录，未开启记录或保留时长为 0 时不清理
+func pruneHistory() {
+	enabled, keep := historyConfig()
+	if !enabled || 0 >= keep {
+		return
+	}
+	if _, err := Prune("", now().Add(-keep)); nil != err {
+		dLogger.Write(dLogger.LeverError, "cron", fmt.Sprintf("清理定时任务执行记录失败: %s", err.Error()))
+	}
+}
+
+// Runs 任务最近的执行记录，按开始时间倒序，name 为空时为全部任务
+func Runs(name string, limit int) ([]RunModelType, error) {
+	if err := dbManager.Init(); nil != err {
+		return nil, err
+	}
+	if 0 >= limit {
+		limit = 20
+	}
+	db := dbManager.GetInstance().Order("start_time DESC, id DESC").Limit(limit)
+	if "" != name {
+		db = db.Where("name = ?", name)
+	}
+	var runList []RunModelType
+	return runList, db.Find(&runList).Error
+}
+
+// Prune 删除任务在 before 之前开始的执行记录，name 为空时为全部任务，返回删除的数量
+func Prune(name string, before time.Time) (int64, error) {
+	if err := dbManager.Init(); nil != err {
+		return 0, err
+	}
+	db := dbManager.GetInstance().Where("start_time < ?", before.In(GetInstance().Location()).Format(timeLayout))
+	if "" != name {
+		db = db.Where("name = ?", name)
+	}
+	result := db.Delete(&RunModelType{})
+	return result.RowsAffected, result.Error
+}
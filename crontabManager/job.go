@@ -0,0 +1,335 @@
+package crontabManager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mini-tiger/fast-api/dError"
+	"github.com/mini-tiger/fast-api/dLogger"
+	"github.com/robfig/cron/v3"
+)
+
+// JobFuncType 任务函数，应用退出超时时 ctx 会被取消
+type JobFuncType func(ctx context.Context) error
+
+// JobType 已注册的定时任务，执行周期和是否启用由 [crontab] 配置决定：
+//
+//	[crontab]
+//	cleanup = @every 1h          ; 覆盖注册时的默认周期
+//	cleanup.enabled = false      ; 停用任务
+//	cleanup.retry = 3            ; 失败后重试次数
+//	cleanup.retryBackoff = 10s   ; 第一次重试前等待的时长，之后按指数增长
+//	cleanup.timeout = 30m        ; 最长执行时间
+//	cleanup.overlap = skip       ; 上次执行未结束时跳过本次，可选 allow、skip、queue
+//	cleanup.catchUp = all        ; 停机错过的执行在启动后补执行，可选 none、once、all
+//	cleanup.catchUpLimit = 7     ; catchUp = all 时最多补执行的次数
+type JobType struct {
+	name        string
+	defaultSpec string
+	fn          JobFuncType
+
+	// spec 生效的执行周期
+	spec    string
+	enabled bool
+	// entryId 调度器中的任务，未调度时为 0
+	entryId cron.EntryID
+
+	// singleton 多实例部署时每个周期只由一个实例执行
+	singleton bool
+
+	// timeout 生效的最长执行时间，defaultTimeout 为 SetTimeout 设置的值
+	timeout        time.Duration
+	defaultTimeout time.Duration
+	// overlap 生效的处理方式，defaultOverlap 为 SetOverlap 设置的值
+	overlap        OverlapType
+	defaultOverlap OverlapType
+	// slot 非 allow 时的执行名额，queued 是否已有排队
+	slot   chan struct{}
+	queued atomic.Bool
+
+	// catchUp 生效的补执行方式，defaultCatchUp 为 SetCatchUp 设置的值
+	catchUp             CatchUpType
+	catchUpLimit        int
+	defaultCatchUp      CatchUpType
+	defaultCatchUpLimit int
+
+	runningCount  atomic.Int32
+	stuckCount    atomic.Int32
+	skipCount     atomic.Int64
+	lockSkipCount atomic.Int64
+	timeoutCount  atomic.Int64
+
+	// retry 生效的重试次数和间隔，defaultRetry 为 SetRetry 设置的值
+	retry               int
+	retryBackoff        time.Duration
+	defaultRetry        int
+	defaultRetryBackoff time.Duration
+	onError             ErrorFuncType
+}
+
+// JobStatusType 任务状态
+type JobStatusType struct {
+	Name    string    `json:"name"`
+	Spec    string    `json:"spec"`
+	Enabled bool      `json:"enabled"`
+	Next    time.Time `json:"next"`
+	Prev    time.Time `json:"prev"`
+	// LastHost 最近一次执行单实例任务的实例
+	LastHost string `json:"lastHost,omitempty"`
+	// Paused 是否已通过 Pause 暂停
+	Paused bool `json:"paused"`
+	// Running 正在执行的数量，Stuck 其中超时后任务函数仍未返回的数量
+	Running int `json:"running"`
+	Stuck   int `json:"stuck"`
+	// Skipped 因上次执行未结束而跳过的次数，LockSkipped 单实例任务因其他实例已执行而跳过的次数，
+	// Timeouts 超时的次数，均为本进程启动以来
+	Skipped     int64 `json:"skipped"`
+	LockSkipped int64 `json:"lockSkipped"`
+	Timeouts    int64 `json:"timeouts"`
+}
+
+// reservedNameMap [crontab] 中的配置项，不能用作任务名称
+var reservedNameMap = map[string]struct{}{"timezone": {}, "history": {}, "historyKeep": {}}
+
+var jobLock sync.Mutex
+var jobMap = map[string]*JobType{}
+
+// Register 注册定时任务，defaultSpec 为标准 cron 表达式或 @every 1h 等描述符，可被 [crontab] <name> 覆盖；
+// 任务名称重复时 panic。任务在 Start 时调度，调度开始后注册的任务立即调度
+func Register(name string, defaultSpec string, fn JobFuncType) *JobType {
+	if "" == name || strings.ContainsAny(name, ". \t") {
+		panic(dError.NewError(fmt.Sprintf("定时任务名称 %s 不能为空或包含 . 和空白字符", name)))
+	}
+	if _, ok := reservedNameMap[name]; ok {
+		panic(dError.NewError(fmt.Sprintf("定时任务名称 %s 与 [crontab] 配置项重名", name)))
+	}
+	jobLock.Lock()
+	if _, ok := jobMap[name]; ok {
+		jobLock.Unlock()
+		panic(dError.NewError(fmt.Sprintf("定时任务 %s 重复注册", name)))
+	}
+	job := &JobType{
+		name:        name,
+		defaultSpec: defaultSpec,
+		fn:          fn,
+		spec:        defaultSpec,
+		enabled:     true,
+		slot:        make(chan struct{}, 1),
+	}
+	jobMap[name] = job
+	jobLock.Unlock()
+
+	if running.Load() {
+		if err := reschedule(); nil != err {
+			dLogger.Write(dLogger.LeverError, "cron", err)
+		}
+	}
+	return job
+}
+
+// Name 任务名称
+func (j *JobType) Name() string {
+	return j.name
+}
+
+// configure 按 [crontab] 配置更新执行周期和是否启用，调用方需持有 jobLock
+func (j *JobType) configure(cronConfig *ConfigType) {
+	j.spec = j.defaultSpec
+	if spec := strings.TrimSpace(cronConfig.Jobs[j.name]); "" != spec {
+		j.spec = spec
+	}
+	j.enabled = true
+	if enabled, ok := cronConfig.Jobs[j.name+".enabled"]; ok {
+		j.enabled, _ = strconv.ParseBool(enabled)
+	}
+	j.configureRetry(cronConfig)
+	j.configureOverlap(cronConfig)
+	j.configureCatchUp(cronConfig)
+}
+
+// schedule 按当前配置重新调度，调用方需持有 jobLock 且调度器已创建
+func (j *JobType) schedule() error {
+	if 0 != j.entryId {
+		server.Remove(j.entryId)
+		j.entryId = 0
+	}
+	if !j.enabled {
+		return nil
+	}
+	entryId, err := server.AddJob(j.spec, cron.FuncJob(j.tick))
+	if nil != err {
+		return dError.NewError(fmt.Sprintf("定时任务 %s 执行周期 %s 错误", j.name, j.spec), err)
+	}
+	j.entryId = entryId
+	return nil
+}
+
+// tick 调度器按周期调用，已暂停的任务跳过
+func (j *JobType) tick() {
+	if j.isPaused() {
+		return
+	}
+	// 调度器在整秒调用，去掉调用的延迟
+	_ = j.fire(now().Truncate(time.Second))
+}
+
+// fire 按处理方式决定是否执行，单实例任务获取本次调度时间的锁后才执行，失败时按配置重试；
+// scheduled 为本次对应的调度时间，手动执行时为零值；返回最终的错误，跳过时返回 nil。
+// 超时后任务函数仍未返回时，执行名额在其返回后才释放，fire 本身不再等待
+func (j *JobType) fire(scheduled time.Time) error {
+	_, err := j.tryFire(scheduled)
+	return err
+}
+
+// tryFire 同 fire，并返回是否执行；跳过的原因已记录日志
+func (j *JobType) tryFire(scheduled time.Time) (bool, error) {
+	leave := j.enter()
+	if nil == leave {
+		return false, nil
+	}
+	releaseList := []func(){leave}
+	pending := &pendingType{}
+	defer func() {
+		pending.release(releaseList)
+	}()
+	jobLock.Lock()
+	singleton := j.singleton
+	jobLock.Unlock()
+	// 手动执行没有对应的调度时间，不通过锁去重
+	if singleton && !scheduled.IsZero() {
+		ok, err := j.acquire(scheduled, j.lockTtl())
+		if nil != err {
+			dLogger.Write(dLogger.LeverError, "cron", fmt.Sprintf("定时任务 %s 获取锁失败，跳过本次执行: %s", j.name, err.Error()))
+			return false, nil
+		}
+		if !ok {
+			j.lockSkipCount.Add(1)
+			dLogger.Write(dLogger.LeverInfo, "cron", fmt.Sprintf("定时任务 %s 调度时间 %s 已由其他实例执行，跳过本次", j.name, scheduled.Format(timeLayout)))
+			return false, nil
+		}
+	}
+	if singleton {
+		if err := j.recordHost(time.Now()); nil != err {
+			dLogger.Write(dLogger.LeverError, "cron", fmt.Sprintf("定时任务 %s 记录执行实例失败: %s", j.name, err.Error()))
+		}
+	}
+	ctx := context.WithValue(jobContext(), pendingKeyType{}, pending)
+	if !scheduled.IsZero() {
+		ctx = context.WithValue(ctx, scheduledKeyType{}, scheduled)
+	}
+	if err := j.execute(ctx); nil != err {
+		dLogger.Write(dLogger.LeverError, "cron", fmt.Sprintf("定时任务 %s 执行失败: %s", j.name, err.Error()))
+		return true, err
+	}
+	if !scheduled.IsZero() {
+		j.recordSuccess(scheduled)
+	}
+	return true, nil
+}
+
+// run 执行一次任务，并写入执行记录
+func (j *JobType) run(ctx context.Context) error {
+	start := now()
+	run := j.startRun(ctx, start)
+	if nil == run {
+		return j.call(ctx)
+	}
+	err := j.call(context.WithValue(ctx, runKeyType{}, run))
+	j.finishRun(run, start, err)
+	return err
+}
+
+// status 任务状态，调用方需持有 jobLock
+func (j *JobType) status() JobStatusType {
+	status := JobStatusType{
+		Name:        j.name,
+		Spec:        j.spec,
+		Enabled:     j.enabled,
+		Running:     int(j.runningCount.Load()),
+		Stuck:       int(j.stuckCount.Load()),
+		Skipped:     j.skipCount.Load(),
+		LockSkipped: j.lockSkipCount.Load(),
+		Timeouts:    j.timeoutCount.Load(),
+	}
+	if 0 != j.entryId && nil != server {
+		entry := server.Entry(j.entryId)
+		// 调度开始前 entry.Next 为空，按执行周期计算
+		status.Next = entry.Schedule.Next(now())
+		status.Prev = entry.Prev
+	}
+	return status
+}
+
+// scheduleAll 按配置调度全部已注册的任务，调度器创建后和配置热加载后调用
+func scheduleAll(cronConfig *ConfigType) error {
+	jobLock.Lock()
+	defer jobLock.Unlock()
+	var errList []error
+	for _, job := range jobMap {
+		spec, enabled := job.spec, job.enabled
+		job.configure(cronConfig)
+		if 0 != job.entryId && spec == job.spec && enabled == job.enabled {
+			continue
+		}
+		if err := job.schedule(); nil != err {
+			errList = append(errList, err)
+		}
+	}
+	if 0 < len(errList) {
+		return dError.NewError("调度定时任务出错", errList...)
+	}
+	return nil
+}
+
+// List 按名称排序的全部已注册任务
+func List() []JobStatusType {
+	jobLock.Lock()
+	list := make([]JobStatusType, 0, len(jobMap))
+	var singletonList []*JobType
+	for _, job := range jobMap {
+		list = append(list, job.status())
+		if job.singleton {
+			singletonList = append(singletonList, job)
+		}
+	}
+	jobLock.Unlock()
+	// 读取 Redis 时不持有 jobLock
+	for _, job := range singletonList {
+		for i := range list {
+			if job.name == list[i].Name {
+				list[i].LastHost = job.lastHost()
+			}
+		}
+	}
+	if pausedMap, err := pausedNames(); nil == err {
+		for i := range list {
+			_, list[i].Paused = pausedMap[list[i].Name]
+		}
+	}
+	sort.Slice(list, func(i, k int) bool {
+		return list[i].Name < list[k].Name
+	})
+	return list
+}
+
+// Get 获取已注册的任务，不存在时返回 nil
+func Get(name string) *JobType {
+	jobLock.Lock()
+	defer jobLock.Unlock()
+	return jobMap[name]
+}
+
+// Run 立即在当前协程执行一次任务，不重试，不影响调度
+func Run(ctx context.Context, name string) error {
+	job := Get(name)
+	if nil == job {
+		return dError.NewError(fmt.Sprintf("定时任务 %s 不存在", name))
+	}
+	return job.run(ctx)
+}
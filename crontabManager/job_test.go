@@ -0,0 +1,542 @@
+package crontabManager
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mini-tiger/fast-api/cache"
+	"github.com/mini-tiger/fast-api/config"
+	"github.com/mini-tiger/fast-api/core"
+	"github.com/robfig/cron/v3"
+)
+
+// useConfig 使用临时目录中内容为 content 的 dev.ini 并重新读取配置，返回改写 dev.ini 的函数；
+// 结束时清空已注册的任务和调度器，避免影响其他用例
+func useConfig(t *testing.T, content string) func(content string) {
+	dir := t.TempDir()
+	writeConfig := func(content string) {
+		if err := os.WriteFile(filepath.Join(dir, "dev.ini"), []byte(content), 0666); nil != err {
+			t.Fatal(err)
+		}
+	}
+	writeConfig(content)
+	core.ConfigDir = dir
+	t.Cleanup(func() {
+		core.ConfigDir = ""
+		jobMap = map[string]*JobType{}
+		lock.Lock()
+		if nil != server {
+			server.Stop()
+		}
+		server = nil
+		lock.Unlock()
+	})
+	if err := config.Reload(); nil != err {
+		t.Fatal(err)
+	}
+	return writeConfig
+}
+
+func TestRegister(t *testing.T) {
+	writeConfig := useConfig(t, "[core]\nserverName = demo\n[crontab]\nhistory = false\ncleanup = @every 2h\nreport.enabled = false\n")
+
+	var runCount int
+	Register("cleanup", "@every 1h", func(ctx context.Context) error {
+		runCount++
+		return nil
+	})
+	Register("report", "0 1 * * *", func(ctx context.Context) error {
+		return nil
+	})
+
+	if err := Init(); nil != err {
+		t.Fatal(err)
+	}
+	if err := reschedule(); nil != err {
+		t.Fatal(err)
+	}
+	list := List()
+	if 2 != len(list) || "cleanup" != list[0].Name || "@every 2h" != list[0].Spec || list[1].Enabled || list[0].Next.IsZero() {
+		t.Fatalf("%+v", list)
+	}
+	if 1 != len(GetInstance().Entries()) {
+		t.Fatal("停用的任务不应调度")
+	}
+	if err := Run(context.Background(), "cleanup"); nil != err || 1 != runCount {
+		t.Fatal("应立即执行一次", err)
+	}
+	if nil == Run(context.Background(), "missing") {
+		t.Fatal("任务不存在时应返回错误")
+	}
+
+	// 热加载后重新调度，执行周期错误的配置被拒绝
+	writeConfig("[core]\nserverName = demo\n[crontab]\ncleanup = @every 3h\n")
+	if err := config.Reload(); nil != err {
+		t.Fatal(err)
+	}
+	list = List()
+	if "@every 3h" != list[0].Spec || !list[1].Enabled || 2 != len(GetInstance().Entries()) {
+		t.Fatalf("%+v", list)
+	}
+	writeConfig("[core]\nserverName = demo\n[crontab]\ncleanup = every day\n")
+	if err := config.Reload(); nil == err {
+		t.Fatal("执行周期错误时应拒绝新配置")
+	}
+	if "@every 3h" != List()[0].Spec {
+		t.Fatal("应保留原配置")
+	}
+
+	defer func() {
+		if nil == recover() {
+			t.Fatal("重复注册应 panic")
+		}
+	}()
+	Register("cleanup", "@every 1h", nil)
+}
+
+func TestLockTtl(t *testing.T) {
+	job := &JobType{name: "sync"}
+	if defaultLockTimeout != job.lockTtl() {
+		t.Fatal("未设置超时时间时应使用默认时长")
+	}
+	if job.SetSingleton(true).SetTimeout(time.Minute); !job.singleton || time.Minute+lockMargin != job.lockTtl() {
+		t.Fatal("锁的过期时间应为超时时间加上保留时长", job.lockTtl())
+	}
+	// 重试期间一直持有锁
+	if job.SetRetry(2, time.Second); 3*(time.Minute+lockMargin)+3*time.Second != job.lockTtl() {
+		t.Fatal("锁的过期时间应包含全部重试及其间隔", job.lockTtl())
+	}
+
+	// @every 按各实例的启动时间计算，调度时间不同无法去重
+	job.defaultSpec = "0 * * * *"
+	jobMap["sync"] = job
+	defer func() {
+		jobMap = map[string]*JobType{}
+	}()
+	if nil != (&ConfigType{}).Validate() || nil == (&ConfigType{Jobs: map[string]string{"sync": "@every 1h"}}).Validate() {
+		t.Fatal("单实例任务的执行周期不能使用 @every")
+	}
+	defer func() {
+		if nil == recover() {
+			t.Fatal("执行周期为 @every 的任务设置单实例应 panic")
+		}
+	}()
+	(&JobType{name: "every", spec: "@every 1h"}).SetSingleton(true)
+}
+
+func TestSetSummary(t *testing.T) {
+	run := &RunModelType{}
+	ctx := context.WithValue(context.Background(), runKeyType{}, run)
+	SetSummary(ctx, strings.Repeat("条", summaryMaxLength+1))
+	if summaryMaxLength != len([]rune(run.Summary)) {
+		t.Fatal("摘要应截断", len([]rune(run.Summary)))
+	}
+	// 没有执行记录时忽略
+	SetSummary(context.Background(), "ok")
+}
+
+func TestRetry(t *testing.T) {
+	useConfig(t, "[core]\nserverName = demo\n[log]\nlevel = error\n[crontab]\nhistory = false\nflaky.retry = 2\nflaky.retryBackoff = 1ms\n")
+
+	var callCount int
+	var failedName string
+	job := Register("flaky", "@every 1h", func(ctx context.Context) error {
+		callCount++
+		if 1 == callCount {
+			panic("boom")
+		}
+		return errors.New("失败")
+	}).SetRetry(5, time.Hour).OnError(func(name string, err error) {
+		failedName = name
+	})
+	if err := Init(); nil != err {
+		t.Fatal(err)
+	}
+	if err := reschedule(); nil != err {
+		t.Fatal(err)
+	}
+	if err := job.execute(context.Background()); nil == err || 3 != callCount || "flaky" != failedName {
+		t.Fatal("panic 应转为错误，并按配置重试 2 次后调用失败回调", err, callCount, failedName)
+	}
+	if time.Second != backoff(0, 1) || 4*time.Second != backoff(time.Second, 3) || maxRetryBackoff != backoff(time.Minute, 20) {
+		t.Fatal("重试间隔应按指数增长且不超过上限")
+	}
+}
+
+func TestOverlap(t *testing.T) {
+	job := &JobType{name: "slow", slot: make(chan struct{}, 1)}
+	job.SetOverlap(OverlapSkip)
+	leave := job.enter()
+	if nil == leave || nil != job.enter() || 1 != job.skipCount.Load() {
+		t.Fatal("上次执行未结束时应跳过本次")
+	}
+	leave()
+
+	job.SetOverlap(OverlapQueue)
+	leave = job.enter()
+	queued := make(chan func())
+	go func() {
+		queued <- job.enter()
+	}()
+	for !job.queued.Load() {
+		time.Sleep(time.Millisecond)
+	}
+	if nil != job.enter() {
+		t.Fatal("已有排队时应跳过本次")
+	}
+	leave()
+	if leave = <-queued; nil == leave || job.queued.Load() {
+		t.Fatal("上次执行结束后应执行排队的一次")
+	}
+	leave()
+}
+
+func TestTimeout(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	job := &JobType{name: "stuck", fn: func(ctx context.Context) error {
+		// 不响应 ctx 的任务同样不会阻塞调度
+		<-block
+		return nil
+	}}
+	job.SetTimeout(10 * time.Millisecond)
+	if err := job.call(context.Background()); !errors.Is(err, context.DeadlineExceeded) || 1 != job.timeoutCount.Load() {
+		t.Fatal("超时后应返回超时错误并计数", err)
+	}
+
+	release := make(chan struct{})
+	job = &JobType{name: "stuck", slot: make(chan struct{}, 1), fn: func(ctx context.Context) error {
+		<-release
+		return nil
+	}}
+	job.SetOverlap(OverlapSkip).SetTimeout(10*time.Millisecond).SetRetry(2, time.Millisecond)
+	if err := job.fire(time.Time{}); !errors.Is(err, context.DeadlineExceeded) || 1 != job.timeoutCount.Load() {
+		t.Fatal("超时后任务函数未返回时不应重试", err, job.timeoutCount.Load())
+	}
+	if nil != job.enter() || 1 != job.runningCount.Load() {
+		t.Fatal("任务函数返回前应保持执行名额")
+	}
+	jobMap["stuck"] = job
+	defer func() {
+		jobMap = map[string]*JobType{}
+	}()
+	jobLock.Lock()
+	status := job.status()
+	jobLock.Unlock()
+	if 1 != status.Stuck || 1 != stuckJobs()["stuck"] {
+		t.Fatal("超时后未返回的任务应在状态和健康检查中报告")
+	}
+	close(release)
+	for deadline := time.Now().Add(time.Second); 0 != job.runningCount.Load() || 0 != len(stuckJobs()); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("任务函数返回后应释放执行名额")
+		}
+	}
+	leave := job.enter()
+	if nil == leave {
+		t.Fatal("任务函数返回后应可以再次执行")
+	}
+	leave()
+}
+
+func TestTrigger(t *testing.T) {
+	useConfig(t, "[core]\nserverName = demo\n[crontab]\nhistory = false\n")
+
+	done := make(chan struct{})
+	Register("notify", "@every 1h", func(ctx context.Context) error {
+		close(done)
+		return nil
+	})
+	if nil == Trigger("missing") {
+		t.Fatal("任务不存在时应返回错误")
+	}
+	if err := Trigger("notify"); nil != err {
+		t.Fatal(err)
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("应在后台执行一次")
+	}
+	if nil == Pause("notify") || nil == Resume("notify") {
+		t.Fatal("未配置 [redis] 时暂停应返回错误")
+	}
+}
+
+func TestMissedTimes(t *testing.T) {
+	schedule, err := cron.ParseStandard("0 1 * * *")
+	if nil != err {
+		t.Fatal(err)
+	}
+	last := time.Date(2026, 10, 10, 1, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+	missedList, count := missedTimes(schedule, last, until, 3)
+	if 5 != count || 3 != len(missedList) || 13 != missedList[0].Day() || 15 != missedList[2].Day() {
+		t.Fatal("应保留最近的 3 次", count, missedList)
+	}
+	if _, count = missedTimes(schedule, until, until, 3); 0 != count {
+		t.Fatal("没有错过的执行")
+	}
+	ctx := context.WithValue(context.Background(), scheduledKeyType{}, last)
+	if !last.Equal(ScheduledTime(ctx)) {
+		t.Fatal("补执行时应返回错过的调度时间")
+	}
+}
+
+func TestRunMissed(t *testing.T) {
+	schedule, err := cron.ParseStandard("0 1 * * *")
+	if nil != err {
+		t.Fatal(err)
+	}
+	last := time.Date(2026, 10, 10, 1, 0, 0, 0, time.UTC)
+	missedList, _ := missedTimes(schedule, last, last.AddDate(0, 0, 3), 10)
+	var scheduledList []time.Time
+	failAt := time.Time{}
+	job := &JobType{name: "settle", slot: make(chan struct{}, 1), fn: func(ctx context.Context) error {
+		scheduledList = append(scheduledList, ScheduledTime(ctx))
+		if ScheduledTime(ctx).Equal(failAt) {
+			return errors.New("失败")
+		}
+		return nil
+	}}
+	job.SetOverlap(OverlapSkip)
+	if skipList := job.runBatch(missedList); 0 != len(skipList) || 3 != len(scheduledList) || !missedList[2].Equal(scheduledList[2]) {
+		t.Fatal("应按调度时间从早到晚逐次补执行", skipList, scheduledList)
+	}
+
+	leave := job.enter()
+	if skipList := job.runBatch(missedList); 3 != len(skipList) || 3 != len(scheduledList) {
+		t.Fatal("上次执行未结束时应跳过并返回每个调度时间", skipList)
+	}
+	leave()
+
+	scheduledList, failAt = nil, missedList[1]
+	if skipList := job.runBatch(missedList); 1 != len(skipList) || !missedList[2].Equal(skipList[0]) || 2 != len(scheduledList) {
+		t.Fatal("失败时应停止并返回之后的调度时间", skipList, scheduledList)
+	}
+
+	scheduledList = nil
+	job.SetSingleton(true)
+	if skipList := job.runBatch(missedList); 3 != len(skipList) || 0 != len(scheduledList) {
+		t.Fatal("未配置 [redis] 时单实例任务应跳过全部补执行", skipList)
+	}
+}
+
+func TestStop(t *testing.T) {
+	useConfig(t, "[core]\nserverName = demo\n[crontab]\nhistory = false\n")
+	if err := Init(); nil != err {
+		t.Fatal(err)
+	}
+
+	started := make(chan struct{})
+	Register("report", "@every 1h", func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err := Trigger("report"); nil != err {
+		t.Fatal(err)
+	}
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("应等待后台执行的任务结束，超时后返回错误", err)
+	}
+	if err := Stop(context.Background()); nil != err {
+		t.Fatal("取消任务的 ctx 后后台执行应结束", err)
+	}
+}
+
+// fakeRedisType 测试用的 Redis 服务，只实现定时任务用到的命令，不处理过期时间；
+// 脚本按 SHA1 由 scriptMap 中的函数模拟，调用时已持有 lock
+type fakeRedisType struct {
+	lock      sync.Mutex
+	stringMap map[string]string
+	hashMap   map[string]map[string]string
+	scriptMap map[string]func(keyList, argList []string) string
+}
+
+// useRedis 启动测试用的 Redis 服务，返回连接它的 [redis] 配置
+func useRedis(t *testing.T) (*fakeRedisType, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatal(err)
+	}
+	fake := &fakeRedisType{stringMap: map[string]string{}, hashMap: map[string]map[string]string{}}
+	fake.scriptMap = map[string]func(keyList, argList []string) string{
+		recordSuccessScript.Hash(): func(keyList, argList []string) string {
+			current, _ := strconv.ParseInt(fake.hashMap[keyList[0]][argList[0]], 10, 64)
+			if value, _ := strconv.ParseInt(argList[1], 10, 64); value <= current {
+				return ":0\r\n"
+			}
+			if nil == fake.hashMap[keyList[0]] {
+				fake.hashMap[keyList[0]] = map[string]string{}
+			}
+			fake.hashMap[keyList[0]][argList[0]] = argList[1]
+			return ":1\r\n"
+		},
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if nil != err {
+				return
+			}
+			go fake.serve(conn)
+		}
+	}()
+	t.Cleanup(func() {
+		_ = cache.Close()
+		_ = listener.Close()
+	})
+	return fake, fmt.Sprintf("[redis]\nhost = 127.0.0.1\nport = %d\n", listener.Addr().(*net.TCPAddr).Port)
+}
+
+// serve 按 RESP2 协议逐条处理命令
+func (f *fakeRedisType) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if nil != err {
+			return
+		}
+		count, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		argList := make([]string, count)
+		for i := range argList {
+			if _, err := reader.ReadString('\n'); nil != err {
+				return
+			}
+			line, err := reader.ReadString('\n')
+			if nil != err {
+				return
+			}
+			argList[i] = strings.TrimSuffix(line, "\r\n")
+		}
+		if _, err := io.WriteString(conn, f.handle(argList)); nil != err {
+			return
+		}
+	}
+}
+
+// handle 执行一条命令并返回回复
+func (f *fakeRedisType) handle(argList []string) string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if command := strings.ToUpper(argList[0]); "EVAL" == command || "EVALSHA" == command {
+		sha := argList[1]
+		if "EVAL" == command {
+			sha = fmt.Sprintf("%x", sha1.Sum([]byte(argList[1])))
+		}
+		script, ok := f.scriptMap[sha]
+		if !ok {
+			return "-NOSCRIPT No matching script\r\n"
+		}
+		keyCount, _ := strconv.Atoi(argList[2])
+		return script(argList[3:3+keyCount], argList[3+keyCount:])
+	}
+	bulk := func(value string) string {
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+	}
+	switch strings.ToUpper(argList[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "SET":
+		for _, arg := range argList[3:] {
+			if _, ok := f.stringMap[argList[1]]; ok && "NX" == strings.ToUpper(arg) {
+				return "$-1\r\n"
+			}
+		}
+		f.stringMap[argList[1]] = argList[2]
+		return "+OK\r\n"
+	case "HSET":
+		if nil == f.hashMap[argList[1]] {
+			f.hashMap[argList[1]] = map[string]string{}
+		}
+		for i := 2; i+1 < len(argList); i += 2 {
+			f.hashMap[argList[1]][argList[i]] = argList[i+1]
+		}
+		return ":1\r\n"
+	case "HGET":
+		if value, ok := f.hashMap[argList[1]][argList[2]]; ok {
+			return bulk(value)
+		}
+		return "$-1\r\n"
+	case "HGETALL":
+		reply := fmt.Sprintf("*%d\r\n", 2*len(f.hashMap[argList[1]]))
+		for field, value := range f.hashMap[argList[1]] {
+			reply += bulk(field) + bulk(value)
+		}
+		return reply
+	case "SISMEMBER":
+		return ":0\r\n"
+	case "SMEMBERS":
+		return "*0\r\n"
+	}
+	return fmt.Sprintf("-ERR unknown command '%s'\r\n", argList[0])
+}
+
+func TestCatchUpRedis(t *testing.T) {
+	fake, redisConfig := useRedis(t)
+	useConfig(t, "[core]\nserverName = demo\n[crontab]\nhistory = false\n"+redisConfig)
+
+	var scheduledList []time.Time
+	job := Register("settle", "0 1 * * *", func(ctx context.Context) error {
+		scheduledList = append(scheduledList, ScheduledTime(ctx))
+		return nil
+	}).SetCatchUp(CatchUpAll, 0)
+	location := GetInstance().Location()
+	until := time.Date(2026, 10, 10, 8, 0, 0, 0, location)
+
+	// 首次启动没有成功记录，以启动时间为起点
+	if last, err := job.lastSuccess(); nil != err || !last.IsZero() {
+		t.Fatal("没有成功记录时应返回零值", last, err)
+	}
+	job.runMissed(until)
+	if last, err := job.lastSuccess(); nil != err || !until.Equal(last) || 0 != len(scheduledList) {
+		t.Fatal("首次启动应记录启动时间作为起点", last, err, scheduledList)
+	}
+
+	job.runMissed(until.AddDate(0, 0, 3))
+	if last, _ := job.lastSuccess(); 3 != len(scheduledList) || !scheduledList[2].Equal(last) {
+		t.Fatal("应补执行停机期间错过的 3 次并记录最近一次", scheduledList, last)
+	}
+	if 1 != len(fake.hashMap) {
+		t.Fatal("成功时间应保存在 Redis 中", fake.hashMap)
+	}
+
+	// 补执行较早的调度时间晚于周期调度成功时不回退
+	job.recordSuccess(until)
+	if last, _ := job.lastSuccess(); !scheduledList[2].Equal(last) {
+		t.Fatal("成功时间只能向后推进", last)
+	}
+
+	// 单实例任务按每个调度时间加锁，其他实例已执行的调度时间跳过，其余照常补执行
+	scheduledList = nil
+	job.SetSingleton(true)
+	missedList, _ := missedTimes(cron.Every(time.Hour), until, until.Add(3*time.Hour), 10)
+	key, err := redisKey(fmt.Sprintf("lock:settle:%d", missedList[1].Unix()))
+	if nil != err {
+		t.Fatal(err)
+	}
+	fake.handle([]string{"SET", key, "other", "NX"})
+	if skipList := job.runBatch(missedList); 1 != len(skipList) || !missedList[1].Equal(skipList[0]) || 2 != len(scheduledList) || 1 != job.lockSkipCount.Load() {
+		t.Fatal("应只跳过已由其他实例执行的调度时间", skipList, scheduledList)
+	}
+	if skipList := job.runBatch(missedList); 3 != len(skipList) || 2 != len(scheduledList) {
+		t.Fatal("已执行的调度时间不应重复执行", skipList, scheduledList)
+	}
+}
@@ -0,0 +1,202 @@
+package crontabManager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mini-tiger/fast-api/dError"
+	"github.com/mini-tiger/fast-api/dLogger"
+)
+
+// 上次执行未结束时本次的处理方式
+const (
+	// OverlapAllow 同时执行，默认值
+	OverlapAllow OverlapType = "allow"
+	// OverlapSkip 跳过本次
+	OverlapSkip OverlapType = "skip"
+	// OverlapQueue 等上次结束后执行，最多排队一次，已有排队时跳过本次
+	OverlapQueue OverlapType = "queue"
+)
+
+type OverlapType string
+
+// SetOverlap 上次执行未结束时本次的处理方式，可被 [crontab] <name>.overlap 覆盖
+func (j *JobType) SetOverlap(overlap OverlapType) *JobType {
+	jobLock.Lock()
+	defer jobLock.Unlock()
+	j.defaultOverlap, j.overlap = overlap, overlap
+	return j
+}
+
+// SetTimeout 任务的最长执行时间，超时后取消传给任务函数的 ctx 并不再等待其返回，也不再重试；
+// 任务函数返回前执行名额不释放，并在健康检查中报告，以便不响应 ctx 的任务卡住时重启实例；可被 [crontab] <name>.timeout 覆盖，为 0 时不限制
+func (j *JobType) SetTimeout(timeout time.Duration) *JobType {
+	jobLock.Lock()
+	defer jobLock.Unlock()
+	j.defaultTimeout, j.timeout = timeout, timeout
+	return j
+}
+
+// configureOverlap 按 [crontab] 配置更新处理方式和超时时间，调用方需持有 jobLock
+func (j *JobType) configureOverlap(cronConfig *ConfigType) {
+	j.overlap, j.timeout = j.defaultOverlap, j.defaultTimeout
+	if value, ok := cronConfig.Jobs[j.name+".overlap"]; ok {
+		j.overlap = OverlapType(value)
+	}
+	if value, ok := cronConfig.Jobs[j.name+".timeout"]; ok {
+		j.timeout, _ = time.ParseDuration(value)
+	}
+}
+
+// validateOverlap 校验 <name>.overlap、<name>.timeout 配置
+func validateOverlap(key, value string) error {
+	switch {
+	case strings.HasSuffix(key, ".overlap"):
+		switch OverlapType(value) {
+		case OverlapAllow, OverlapSkip, OverlapQueue:
+		default:
+			return fmt.Errorf("配置 [crontab] %s = %s 错误: 可选值 allow、skip、queue", key, value)
+		}
+	case strings.HasSuffix(key, ".timeout"):
+		if timeout, err := time.ParseDuration(value); nil != err || 0 > timeout {
+			return fmt.Errorf("配置 [crontab] %s = %s 错误: 应为不小于 0 的时长", key, value)
+		}
+	}
+	return nil
+}
+
+// enter 按处理方式开始一次调度，返回结束时调用的函数；需要跳过本次时返回 nil
+func (j *JobType) enter() func() {
+	jobLock.Lock()
+	overlap := j.overlap
+	jobLock.Unlock()
+
+	if "" == overlap || OverlapAllow == overlap {
+		j.runningCount.Add(1)
+		return func() {
+			j.runningCount.Add(-1)
+		}
+	}
+	select {
+	case j.slot <- struct{}{}:
+	default:
+		if OverlapQueue != overlap || !j.queued.CompareAndSwap(false, true) {
+			j.skipCount.Add(1)
+			dLogger.Write(dLogger.LeverWaning, "cron", fmt.Sprintf("定时任务 %s 上次执行未结束，跳过本次", j.name))
+			return nil
+		}
+		select {
+		case j.slot <- struct{}{}:
+			j.queued.Store(false)
+		case <-jobContext().Done():
+			j.queued.Store(false)
+			return nil
+		}
+	}
+	j.runningCount.Add(1)
+	return func() {
+		j.runningCount.Add(-1)
+		<-j.slot
+	}
+}
+
+// pendingKeyType 未返回的任务函数在 ctx 中的键
+type pendingKeyType struct{}
+
+// pendingType 超时后仍未返回的任务函数，本次调度的执行名额在它们返回后才释放
+type pendingType struct {
+	lock     sync.Mutex
+	doneList []chan error
+}
+
+// add 登记超时后仍未返回的任务函数
+func (p *pendingType) add(done chan error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.doneList = append(p.doneList, done)
+}
+
+// abandoned 是否有超时后仍未返回的任务函数
+func (p *pendingType) abandoned() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return 0 < len(p.doneList)
+}
+
+// release 依次调用 releaseList；有未返回的任务函数时在后台等待其返回后再调用，不阻塞调度
+func (p *pendingType) release(releaseList []func()) {
+	p.lock.Lock()
+	doneList := p.doneList
+	p.lock.Unlock()
+	releaseAll := func() {
+		for i := len(releaseList) - 1; 0 <= i; i-- {
+			releaseList[i]()
+		}
+	}
+	if 0 == len(doneList) {
+		releaseAll()
+		return
+	}
+	// 调用方在调度器中或已登记的后台执行中，停止时同样会等待
+	goBackground(func() {
+		for _, done := range doneList {
+			<-done
+		}
+		releaseAll()
+	})
+}
+
+// isAbandoned ctx 所在的调度中是否有超时后仍未返回的任务函数
+func isAbandoned(ctx context.Context) bool {
+	pending, ok := ctx.Value(pendingKeyType{}).(*pendingType)
+	return ok && pending.abandoned()
+}
+
+// call 调用任务函数，超过最长执行时间时取消 ctx 并返回超时错误，不再等待任务函数返回，以免阻塞之后的调度；
+// 任务函数返回前本次调度的执行名额不会释放，与设置的处理方式一致
+func (j *JobType) call(ctx context.Context) error {
+	jobLock.Lock()
+	timeout := j.timeout
+	jobLock.Unlock()
+	if 0 >= timeout {
+		return j.safeCall(ctx)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	// state 为 0 时执行中，为 1 时已返回，为 2 时已超时，任务函数返回前计入 stuckCount
+	var state atomic.Int32
+	go func() {
+		err := j.safeCall(timeoutCtx)
+		if !state.CompareAndSwap(0, 1) {
+			j.stuckCount.Add(-1)
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if nil == err || !errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) || nil != ctx.Err() {
+			return err
+		}
+	case <-timeoutCtx.Done():
+		if nil != ctx.Err() {
+			return ctx.Err()
+		}
+	}
+	j.timeoutCount.Add(1)
+	if state.CompareAndSwap(0, 2) {
+		j.stuckCount.Add(1)
+	}
+	if pending, ok := ctx.Value(pendingKeyType{}).(*pendingType); ok {
+		pending.add(done)
+	}
+	err := dError.NewError(fmt.Sprintf("定时任务 %s 执行超过 %s", j.name, timeout), context.DeadlineExceeded)
+	dLogger.Write(dLogger.LeverError, "cron", err.Error())
+	return err
+}
@@ -0,0 +1,101 @@
+package crontabManager
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mini-tiger/fast-api/cache"
+	"github.com/mini-tiger/fast-api/config"
+	"github.com/mini-tiger/fast-api/dError"
+	"github.com/mini-tiger/fast-api/dLogger"
+)
+
+// pausedKey 已暂停任务名称的集合，保存在默认实例 [redis] 中，对全部实例生效
+func pausedKey() (string, error) {
+	return redisKey("paused")
+}
+
+// Trigger 立即在后台按调度的方式执行一次任务，已暂停的任务同样执行，正在停止时返回错误；
+// 与周期调度一样遵循处理方式、超时和重试设置；没有对应的调度时间，单实例任务不通过锁去重
+func Trigger(name string) error {
+	job := Get(name)
+	if nil == job {
+		return dError.NewError(fmt.Sprintf("定时任务 %s 不存在", name))
+	}
+	return startBackground(func() {
+		defer recoverPanic(name)
+		_ = job.fire(time.Time{})
+	})
+}
+
+// Pause 暂停任务，暂停状态保存在 Redis 中，对全部实例生效，重启后仍然保持；需要配置 [redis]
+func Pause(name string) error {
+	key, err := checkPause(name)
+	if nil != err {
+		return err
+	}
+	if err := cache.SAdd(key, name); nil != err {
+		return dError.NewError(fmt.Sprintf("暂停定时任务 %s 失败", name), err)
+	}
+	return nil
+}
+
+// Resume 恢复已暂停的任务，从下一个周期开始执行
+func Resume(name string) error {
+	key, err := checkPause(name)
+	if nil != err {
+		return err
+	}
+	if err := cache.SRem(key, name); nil != err {
+		return dError.NewError(fmt.Sprintf("恢复定时任务 %s 失败", name), err)
+	}
+	return nil
+}
+
+// checkPause 检查任务是否存在及是否配置了 [redis]，返回暂停集合的键
+func checkPause(name string) (string, error) {
+	if nil == Get(name) {
+		return "", dError.NewError(fmt.Sprintf("定时任务 %s 不存在", name))
+	}
+	if !config.HasSection("redis") {
+		return "", dError.NewError("暂停定时任务需要配置 [redis]")
+	}
+	return pausedKey()
+}
+
+// pausedNames 已暂停的任务名称，未配置 [redis] 时为空
+func pausedNames() (map[string]struct{}, error) {
+	pausedMap := map[string]struct{}{}
+	if !config.HasSection("redis") {
+		return pausedMap, nil
+	}
+	key, err := pausedKey()
+	if nil != err {
+		return nil, err
+	}
+	nameList, err := cache.SMembers(key)
+	if nil != err {
+		return nil, err
+	}
+	for _, name := range nameList {
+		pausedMap[name] = struct{}{}
+	}
+	return pausedMap, nil
+}
+
+// isPaused 任务是否已暂停，读取 Redis 失败时照常执行
+func (j *JobType) isPaused() bool {
+	if !config.HasSection("redis") {
+		return false
+	}
+	key, err := pausedKey()
+	if nil != err {
+		return false
+	}
+	paused, err := cache.SIsMember(key, j.name)
+	if nil != err {
+		dLogger.Write(dLogger.LeverWaning, "cron", fmt.Sprintf("定时任务 %s 读取暂停状态失败，照常执行: %s", j.name, err.Error()))
+		return false
+	}
+	return paused
+}
@@ -0,0 +1,132 @@
+package crontabManager
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mini-tiger/fast-api/dError"
+	"github.com/mini-tiger/fast-api/dLogger"
+	"github.com/robfig/cron/v3"
+)
+
+// defaultRetryBackoff 设置了重试次数但未设置间隔时，第一次重试前等待的时长
+const defaultRetryBackoff = time.Second
+
+// maxRetryBackoff 重试间隔按指数增长的上限
+const maxRetryBackoff = 10 * time.Minute
+
+// ErrorFuncType 任务失败的回调，重试全部失败后调用，err 为最后一次的错误
+type ErrorFuncType func(name string, err error)
+
+// SetRetry 任务失败后重试 retry 次，第 n 次重试前等待 backoff * 2^(n-1)，最长 10 分钟；
+// 可被 [crontab] <name>.retry、<name>.retryBackoff 覆盖
+func (j *JobType) SetRetry(retry int, backoff time.Duration) *JobType {
+	jobLock.Lock()
+	defer jobLock.Unlock()
+	j.defaultRetry, j.defaultRetryBackoff = retry, backoff
+	j.retry, j.retryBackoff = retry, backoff
+	return j
+}
+
+// OnError 设置任务失败的回调，如发送告警；回调中的 panic 会被恢复
+func (j *JobType) OnError(fn ErrorFuncType) *JobType {
+	jobLock.Lock()
+	defer jobLock.Unlock()
+	j.onError = fn
+	return j
+}
+
+// configureRetry 按 [crontab] 配置更新重试次数和间隔，调用方需持有 jobLock
+func (j *JobType) configureRetry(cronConfig *ConfigType) {
+	j.retry, j.retryBackoff = j.defaultRetry, j.defaultRetryBackoff
+	if value, ok := cronConfig.Jobs[j.name+".retry"]; ok {
+		j.retry, _ = strconv.Atoi(value)
+	}
+	if value, ok := cronConfig.Jobs[j.name+".retryBackoff"]; ok {
+		j.retryBackoff, _ = time.ParseDuration(value)
+	}
+}
+
+// validateRetry 校验 <name>.retry、<name>.retryBackoff 配置
+func validateRetry(key, value string) error {
+	switch {
+	case strings.HasSuffix(key, ".retry"):
+		if retry, err := strconv.Atoi(value); nil != err || 0 > retry {
+			return fmt.Errorf("配置 [crontab] %s = %s 错误: 应为不小于 0 的整数", key, value)
+		}
+	case strings.HasSuffix(key, ".retryBackoff"):
+		if backoff, err := time.ParseDuration(value); nil != err || 0 > backoff {
+			return fmt.Errorf("配置 [crontab] %s = %s 错误: 应为不小于 0 的时长", key, value)
+		}
+	}
+	return nil
+}
+
+// backoff 第 attempt 次重试前等待的时长
+func backoff(base time.Duration, attempt int) time.Duration {
+	if 0 >= base {
+		base = defaultRetryBackoff
+	}
+	delay := base
+	for i := 1; i < attempt && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryBackoff)
+}
+
+// execute 执行任务，失败时按配置重试，全部失败后调用失败回调
+func (j *JobType) execute(ctx context.Context) error {
+	jobLock.Lock()
+	retry, retryBackoff, onError := j.retry, j.retryBackoff, j.onError
+	jobLock.Unlock()
+
+	err := j.run(ctx)
+	// 超时后任务函数仍未返回时不再重试，以免同时执行两份
+	for attempt := 1; nil != err && attempt <= retry && !isAbandoned(ctx); attempt++ {
+		delay := backoff(retryBackoff, attempt)
+		dLogger.Write(dLogger.LeverWaning, "cron", fmt.Sprintf("定时任务 %s 执行失败，%s 后第 %d 次重试: %s", j.name, delay, attempt, err.Error()))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		err = j.run(ctx)
+	}
+	if nil != err && nil != onError {
+		func() {
+			defer recoverPanic(j.name + " 失败回调")
+			onError(j.name, err)
+		}()
+	}
+	return err
+}
+
+// safeCall 调用任务函数，panic 转为错误并连同调用栈记录到 dLogger
+func (j *JobType) safeCall(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); nil != r {
+			dLogger.Write(dLogger.LeverError, "cron", fmt.Sprintf("定时任务 %s panic: %v\n%s", j.name, r, debug.Stack()))
+			err = dError.NewError(fmt.Sprintf("定时任务 %s panic: %v", j.name, r))
+		}
+	}()
+	return j.fn(ctx)
+}
+
+// recoverPanic 恢复 panic 并连同调用栈记录到 dLogger，需在 defer 中直接调用
+func recoverPanic(name string) {
+	if r := recover(); nil != r {
+		dLogger.Write(dLogger.LeverError, "cron", fmt.Sprintf("定时任务 %s panic: %v\n%s", name, r, debug.Stack()))
+	}
+}
+
+// recoverWrapper 调度器的默认 JobWrapper，兜底恢复任务函数之外（如加锁、写执行记录）的 panic，避免进程退出
+func recoverWrapper(job cron.Job) cron.Job {
+	return cron.FuncJob(func() {
+		defer recoverPanic("调度")
+		job.Run()
+	})
+}
@@ -0,0 +1,111 @@
+package crontabManager
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mini-tiger/fast-api/cache"
+	"github.com/mini-tiger/fast-api/config"
+	"github.com/mini-tiger/fast-api/core"
+	"github.com/mini-tiger/fast-api/dError"
+)
+
+// defaultLockTimeout 未设置超时时间的单实例任务持有锁的时长
+const defaultLockTimeout = 30 * time.Minute
+
+// lockMargin 锁比超时时间多保留的时长，用于吸收多实例之间的时钟误差
+const lockMargin = 5 * time.Second
+
+// hostName 当前实例的主机名，记录在任务的最近执行信息中
+var hostName = func() string {
+	name, err := os.Hostname()
+	if nil != err || "" == name {
+		return "unknown"
+	}
+	return name
+}()
+
+// SetSingleton 多实例部署时每个周期只由一个实例执行，通过默认实例 [redis] 中按调度时间区分的锁实现，需要配置 [redis]；
+// 本周期已由其他实例执行或 Redis 不可用时本实例跳过该周期，手动执行不受限制。
+// 执行周期不能使用 @every，各实例按各自的启动时间计算，调度时间不同无法去重，否则 panic
+func (j *JobType) SetSingleton(singleton bool) *JobType {
+	jobLock.Lock()
+	defer jobLock.Unlock()
+	if singleton && isEvery(j.spec) {
+		panic(dError.NewError(fmt.Sprintf("单实例任务 %s 的执行周期 %s 不能使用 @every", j.name, j.spec)))
+	}
+	j.singleton = singleton
+	return j
+}
+
+// isEvery 执行周期是否为 @every，按启动时间计算，不同实例的调度时间不同
+func isEvery(spec string) bool {
+	return strings.HasPrefix(strings.TrimSpace(spec), "@every")
+}
+
+// redisKey 任务在 Redis 中的键，如 cron:<serverName>:<mode>:last:<name>
+func (j *JobType) redisKey(kind string) (string, error) {
+	return redisKey(kind + ":" + j.name)
+}
+
+// redisKey 定时任务在 Redis 中的键，不同服务、环境共用 Redis 时互不影响
+func redisKey(name string) (string, error) {
+	coreConfig, err := config.GetCoreConfig()
+	if nil != err {
+		return "", err
+	}
+	return fmt.Sprintf("cron:%s:%s:%s", coreConfig.ServerName, core.Mode, name), nil
+}
+
+// lockTtl 锁的过期时间，包含全部重试及其间隔，由超时时间和重试设置决定
+func (j *JobType) lockTtl() time.Duration {
+	jobLock.Lock()
+	defer jobLock.Unlock()
+	attempt := defaultLockTimeout
+	if 0 < j.timeout {
+		attempt = j.timeout + lockMargin
+	}
+	ttl := attempt
+	for i := 1; i <= j.retry; i++ {
+		ttl += backoff(j.retryBackoff, i) + attempt
+	}
+	return ttl
+}
+
+// acquire 获取任务在调度时间 scheduled 的锁，返回是否获取成功；锁不主动释放，ttl 后过期，
+// 以免其他实例的时钟略慢时在同一调度时间再执行一次
+func (j *JobType) acquire(scheduled time.Time, ttl time.Duration) (bool, error) {
+	if !config.HasSection("redis") {
+		return false, dError.NewError("单实例任务需要配置 [redis]")
+	}
+	key, err := redisKey(fmt.Sprintf("lock:%s:%d", j.name, scheduled.Unix()))
+	if nil != err {
+		return false, err
+	}
+	return cache.Lock(key, hostName, ttl)
+}
+
+// recordHost 记录最近一次执行任务的实例和开始时间
+func (j *JobType) recordHost(start time.Time) error {
+	key, err := j.redisKey("last")
+	if nil != err {
+		return err
+	}
+	if err := cache.HSet(key, "host", hostName); nil != err {
+		return err
+	}
+	return cache.HSet(key, "start", strconv.FormatInt(start.Unix(), 10))
+}
+
+// lastHost 最近一次执行单实例任务的实例，未执行过或读取失败时为空
+func (j *JobType) lastHost() string {
+	key, err := j.redisKey("last")
+	if nil != err {
+		return ""
+	}
+	host, _ := cache.HGet(key, "host")
+	return host
+}
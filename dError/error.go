@@ -2,29 +2,66 @@ package dError
 
 type ErrorType struct {
 	SourceErr []error
-	UserMag   string
+	// UserMag 展示给用户的信息，不包含原始错误
+	UserMag string
+	// Code 业务错误码，为 0 时使用 HttpStatus
+	Code int
+	// HttpStatus 返回给客户端的 HTTP 状态码，为 0 时按 500 处理
+	HttpStatus int
 }
 
 func NewError(userMag string, sourceErrList ...error) *ErrorType {
-	//var err error
-	//if 0 == len(sourceErrList) {
-	//	err = errors.New("")
-	//} else {
-	//	err = sourceErrList[0]
-	//}
-	for _, sourceErr := range sourceErrList {
-		userMag += "\n" + sourceErr.Error()
-	}
 	return &ErrorType{
 		UserMag:   userMag,
 		SourceErr: sourceErrList,
 	}
 }
 
+// Error 用户信息及全部原始错误，每个错误一行
 func (e *ErrorType) Error() string {
-	return e.UserMag
+	message := e.UserMag
+	for _, sourceErr := range e.SourceErr {
+		if nil == sourceErr {
+			continue
+		}
+		message += "\n" + sourceErr.Error()
+	}
+	return message
 }
 
 func (e *ErrorType) GetContent() *ErrorType {
 	return e
 }
+
+// SetCode 设置业务错误码
+func (e *ErrorType) SetCode(code int) *ErrorType {
+	e.Code = code
+	return e
+}
+
+// SetHttpStatus 设置 HTTP 状态码
+func (e *ErrorType) SetHttpStatus(httpStatus int) *ErrorType {
+	e.HttpStatus = httpStatus
+	return e
+}
+
+// GetHttpStatus 获取 HTTP 状态码，未设置时为 500
+func (e *ErrorType) GetHttpStatus() int {
+	if 0 == e.HttpStatus {
+		return 500
+	}
+	return e.HttpStatus
+}
+
+// GetCode 获取业务错误码，未设置时与 HTTP 状态码相同
+func (e *ErrorType) GetCode() int {
+	if 0 == e.Code {
+		return e.GetHttpStatus()
+	}
+	return e.Code
+}
+
+// Unwrap 返回原始错误列表，支持 errors.Is / errors.As
+func (e *ErrorType) Unwrap() []error {
+	return e.SourceErr
+}
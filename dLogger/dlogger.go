@@ -1,12 +1,15 @@
 package dLogger
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/mini-tiger/fast-api/config"
 	"github.com/mini-tiger/fast-api/core"
+	"github.com/mini-tiger/fast-api/dError"
 )
 
 // 错误等级
@@ -18,28 +21,175 @@ const (
 
 type LogLevelType string
 
+// levelWeightMap 错误等级的高低，低于 [log] level 的日志不写入
+var levelWeightMap = map[LogLevelType]int{
+	LeverInfo:   0,
+	LeverWaning: 1,
+	LeverError:  2,
+}
+
 var source string
 var mode core.ModeType
 
-// 初始化
+// location CreateTime 使用的时区，[log] timezone 未配置时使用全局时区
+var location *time.Location
+var lock sync.Mutex
+var initialized bool
+
+// minLevel 最低写入等级，随配置热加载更新
+var minLevel = LeverInfo
+
+// writeGroup 未完成的异步写入
+var writeGroup sync.WaitGroup
+
+// stopping Flush 开始后为 true，之后的日志改为同步写入，避免 writeGroup.Add 与 Wait 同时调用
+var stopping bool
+var writeLock sync.Mutex
+
+// ConfigType [log] 配置
+type ConfigType struct {
+	// Timezone 日志时间使用的时区，为空时使用全局时区
+	Timezone string `ini:"timezone"`
+	// Level 最低写入等级：info、warning、error
+	Level LogLevelType `ini:"level" default:"info"`
+}
+
+// Validate 校验时区和等级，热加载时不合法的新配置会被拒绝
+func (c *ConfigType) Validate() error {
+	if "" != c.Timezone {
+		if _, err := core.LoadLocation(c.Timezone); nil != err {
+			return dError.NewError("配置 [log] timezone 错误", err)
+		}
+	}
+	if _, ok := levelWeightMap[c.Level]; !ok {
+		return dError.NewError(fmt.Sprintf("配置 [log] level 错误，可选值 info、warning、error，当前为 %s", c.Level))
+	}
+	return nil
+}
+
+// GetConfig 读取并校验 [log] 配置
+func GetConfig() (*ConfigType, error) {
+	logConfig := &ConfigType{}
+	return logConfig, config.Bind("log", logConfig)
+}
+
 func init() {
-	source = config.GetInstance().Section("core").Key("serverName").Value()
+	core.RegisterComponent(&core.ComponentType{
+		Name:    core.ComponentLogger,
+		Order:   50,
+		Depends: []string{core.ComponentConfig, core.ComponentMysql},
+		Validate: func() error {
+			_, err := GetConfig()
+			return err
+		},
+		Init: Init,
+		Stop: Flush,
+	})
+	config.OnChange("log", reload)
+	config.OnReloadError(func(err error) {
+		Write(LeverError, "config", err)
+	})
+}
+
+// Init 读取日志来源等配置，已初始化时直接返回
+func Init() error {
+	lock.Lock()
+	defer lock.Unlock()
+	if initialized {
+		return nil
+	}
+	coreConfig, err := config.GetCoreConfig()
+	if nil != err {
+		return err
+	}
+	logConfig, err := GetConfig()
+	if nil != err {
+		return err
+	}
+	source = coreConfig.ServerName
 	mode = core.Mode
+	apply(logConfig)
+	config.Register("log", &ConfigType{})
+	initialized = true
+	return nil
+}
+
+// apply 应用时区和等级
+func apply(logConfig *ConfigType) {
+	location = time.Local
+	if "" != logConfig.Timezone {
+		location, _ = core.LoadLocation(logConfig.Timezone)
+	}
+	minLevel = logConfig.Level
+}
+
+// reload 配置热加载后更新时区和等级，未初始化时等首次写入再读取
+func reload() {
+	lock.Lock()
+	defer lock.Unlock()
+	if !initialized {
+		return
+	}
+	logConfig, err := GetConfig()
+	if nil != err {
+		return
+	}
+	apply(logConfig)
+}
+
+// enabled 该等级的日志是否需要写入，读取配置失败时照常写入以便输出错误
+func enabled(logLevel LogLevelType) bool {
+	if err := Init(); nil != err {
+		return true
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	return levelWeightMap[logLevel] >= levelWeightMap[minLevel]
 }
 
 // Write 写入日志到logStash
 func Write(logLevel LogLevelType, typeString string, message any) {
-	if core.Mode == core.Dev {
+	if !enabled(logLevel) {
+		return
+	}
+	writeLock.Lock()
+	if core.Mode == core.Dev || stopping {
+		writeLock.Unlock()
+		toWrite(logLevel, typeString, message)
+		return
+	}
+	// 开启一个携程异步写入
+	writeGroup.Add(1)
+	writeLock.Unlock()
+	go func() {
+		defer writeGroup.Done()
 		toWrite(logLevel, typeString, message)
-	} else {
-		// 开启一个携程异步写入
-		go func() {
-			toWrite(logLevel, typeString, message)
-		}()
+	}()
+}
+
+// Flush 等待所有异步写入完成，ctx 超时则返回错误；之后的日志改为同步写入
+func Flush(ctx context.Context) error {
+	writeLock.Lock()
+	stopping = true
+	writeLock.Unlock()
+	done := make(chan struct{})
+	go func() {
+		writeGroup.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
 func toWrite(logLevel LogLevelType, typeString string, message any) {
+	if err := Init(); nil != err {
+		fmt.Printf("写入日志失败： %s", err.Error())
+		return
+	}
 	messageNew := ""
 	switch v := message.(type) {
 	case string:
@@ -57,13 +207,16 @@ func toWrite(logLevel LogLevelType, typeString string, message any) {
 	if core.Mode == core.Dev {
 		fmt.Printf("[%s][%s][%s]\n", logLevel, typeString, messageNew)
 	}
+	buildInfo := core.GetBuildInfo()
 	logData := &LogModelType{
 		Source:     source,
 		Mode:       mode,
+		Version:    buildInfo.Version,
+		GitCommit:  buildInfo.GitCommit,
 		LogLevel:   logLevel,
 		Type:       typeString,
 		Message:    messageNew,
-		CreateTime: time.Now().Format("2006-01-02 15:04:05"),
+		CreateTime: time.Now().In(location).Format("2006-01-02 15:04:05"),
 	}
 	_, err := logData.Create()
 	if nil != err {
@@ -1,14 +1,19 @@
 package dLogger
 
 import (
+	"sync"
+
 	"github.com/mini-tiger/fast-api/core"
 	"github.com/mini-tiger/fast-api/dbManager"
+	"gorm.io/gorm"
 )
 
 type LogModelType struct {
 	Id         int           `gorm:"primaryKey"`
 	Source     string        `json:"source"`
 	Mode       core.ModeType `json:"mode"`
+	Version    string        `gorm:"size:64" json:"version"`
+	GitCommit  string        `gorm:"size:64" json:"git_commit"`
 	LogLevel   LogLevelType  `json:"log_level"`
 	Type       string        `json:"type"`
 	Message    string        `json:"message"`
@@ -19,7 +24,64 @@ func (l *LogModelType) TableName() string {
 	return "log"
 }
 
+func init() {
+	// log 表增加构建信息字段，执行 migrate up 后生效
+	dbManager.RegisterMigration(&dbManager.MigrationType{
+		Version: "20261017000000",
+		Name:    "log 表增加 version、git_commit 字段",
+		Up: func(tx *gorm.DB) error {
+			for _, field := range []string{"Version", "GitCommit"} {
+				if tx.Migrator().HasColumn(&LogModelType{}, field) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&LogModelType{}, field); nil != err {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, field := range []string{"Version", "GitCommit"} {
+				if !tx.Migrator().HasColumn(&LogModelType{}, field) {
+					continue
+				}
+				if err := tx.Migrator().DropColumn(&LogModelType{}, field); nil != err {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
+
+// buildColumnList log 表缺少的构建信息字段，写入时省略，执行 migrate up 并重启后写入
+var buildColumnList []string
+var buildColumnChecked bool
+var buildColumnLock sync.Mutex
+
+// missingBuildColumns 检查 log 表缺少的构建信息字段，只检查一次
+func missingBuildColumns(db *gorm.DB) []string {
+	buildColumnLock.Lock()
+	defer buildColumnLock.Unlock()
+	if !buildColumnChecked {
+		for _, field := range []string{"Version", "GitCommit"} {
+			if !db.Migrator().HasColumn(&LogModelType{}, field) {
+				buildColumnList = append(buildColumnList, field)
+			}
+		}
+		buildColumnChecked = true
+	}
+	return buildColumnList
+}
+
 func (l *LogModelType) Create() (int64, error) {
-	db := dbManager.GetInstance().Create(l)
+	if err := dbManager.Init(); nil != err {
+		return 0, err
+	}
+	db := dbManager.GetInstance()
+	if missingList := missingBuildColumns(db); 0 < len(missingList) {
+		db = db.Omit(missingList...)
+	}
+	db = db.Create(l)
 	return db.RowsAffected, db.Error
 }
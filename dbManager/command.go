@@ -0,0 +1,58 @@
+package dbManager
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/mini-tiger/fast-api/command"
+	"github.com/mini-tiger/fast-api/core"
+)
+
+func init() {
+	command.Register("migrate up", &command.CommandType{
+		Usage:      "执行全部未执行的数据库迁移",
+		Components: []string{core.ComponentMysql},
+		Run: func(c *command.ContextType) error {
+			versionList, err := MigrateUp()
+			for _, version := range versionList {
+				_, _ = fmt.Fprintf(c.Out, "已执行 %s\n", version)
+			}
+			if nil == err && 0 == len(versionList) {
+				_, _ = fmt.Fprintln(c.Out, "没有需要执行的迁移")
+			}
+			return err
+		},
+	})
+	command.Register("migrate down", &command.CommandType{
+		Usage:      "回滚最近执行的数据库迁移",
+		Components: []string{core.ComponentMysql},
+		Flags: func(flagSet *flag.FlagSet) {
+			flagSet.Int("steps", 1, "回滚的迁移数量")
+		},
+		Run: func(c *command.ContextType) error {
+			versionList, err := MigrateDown(c.Int("steps"))
+			for _, version := range versionList {
+				_, _ = fmt.Fprintf(c.Out, "已回滚 %s\n", version)
+			}
+			return err
+		},
+	})
+	command.Register("migrate status", &command.CommandType{
+		Usage:      "查看数据库迁移状态",
+		Components: []string{core.ComponentMysql},
+		Run: func(c *command.ContextType) error {
+			statusList, err := MigrateStatus()
+			if nil != err {
+				return err
+			}
+			for _, status := range statusList {
+				appliedTime := "未执行"
+				if status.Applied {
+					appliedTime = status.AppliedTime
+				}
+				_, _ = fmt.Fprintf(c.Out, "%-20s %-20s %s\n", status.Version, appliedTime, status.Name)
+			}
+			return nil
+		},
+	})
+}
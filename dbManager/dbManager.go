@@ -1,9 +1,13 @@
 package dbManager
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/mini-tiger/fast-api/config"
@@ -14,29 +18,105 @@ import (
 	"gorm.io/gorm/logger"
 )
 
-var db *gorm.DB
+// dbMap 已连接的数据库，键为实例名称，默认实例为空字符串
+var dbMap = map[string]*gorm.DB{}
+var lock sync.Mutex
+
+// ConfigType [mysql] 配置，命名实例为 [mysql.<name>]，未配置的项继承 [mysql]
+type ConfigType struct {
+	Username string `ini:"username" required:"true"`
+	Password string `ini:"password"`
+	Host     string `ini:"host" default:"127.0.0.1"`
+	Port     int    `ini:"port" default:"3306" range:"1,65535"`
+	Dbname   string `ini:"dbname" required:"true"`
+	// Timeout 建立连接的超时时间
+	Timeout time.Duration `ini:"timeout" default:"10s" range:"1s,"`
+	// MaxIdleConns 空闲连接池中连接的最大数量
+	MaxIdleConns int `ini:"maxIdleConns" default:"10" range:"0,"`
+	// MaxOpenConns 打开数据库连接的最大数量
+	MaxOpenConns int `ini:"maxOpenConns" default:"100" range:"1,"`
+	// ConnMaxLifetime 连接可复用的最大时间
+	ConnMaxLifetime time.Duration `ini:"connMaxLifetime" default:"1h" range:"1s,"`
+}
+
+// GetConfig 读取并校验 [mysql] 配置
+func GetConfig() (*ConfigType, error) {
+	return GetNamedConfig("")
+}
+
+// GetNamedConfig 读取并校验命名实例 [mysql.<name>] 的配置，name 为空时为 [mysql]
+func GetNamedConfig(name string) (*ConfigType, error) {
+	mysqlConfig := &ConfigType{}
+	section := config.SectionName("mysql", name)
+	if "" != name && !config.HasSection(section) {
+		return mysqlConfig, dError.NewError(fmt.Sprintf("缺少配置分组 [%s]", section))
+	}
+	return mysqlConfig, config.Bind(section, mysqlConfig)
+}
+
+// validate 校验默认实例及全部命名实例的配置
+func validate() error {
+	var errList []error
+	for _, name := range append([]string{""}, config.Names("mysql")...) {
+		if _, err := GetNamedConfig(name); nil != err {
+			errList = append(errList, err)
+		}
+	}
+	if 0 < len(errList) {
+		return dError.NewError("数据库配置错误", errList...)
+	}
+	return nil
+}
 
 func init() {
-	mysqlConfig := config.GetInstance().Section("mysql")
-	username := mysqlConfig.Key("username").Value()
-	password := mysqlConfig.Key("password").Value()
-	host := mysqlConfig.Key("host").Value()
-	port := mysqlConfig.Key("port").Value()
-	dbname := mysqlConfig.Key("dbname").Value()
-	timeout := mysqlConfig.Key("timeout").Value()
+	core.RegisterComponent(&core.ComponentType{
+		Name:     core.ComponentMysql,
+		Order:    20,
+		Depends:  []string{core.ComponentConfig},
+		Validate: validate,
+		Init:     Init,
+		Stop:     Close,
+	})
+	config.OnChange("mysql", reloadPool)
+}
+
+// Init 连接默认实例及全部命名实例，已连接的实例直接跳过
+func Init() error {
+	lock.Lock()
+	defer lock.Unlock()
+	if err := config.Load(); nil != err {
+		return err
+	}
+	for _, name := range append([]string{""}, config.Names("mysql")...) {
+		if _, err := connect(name); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// connect 连接实例，已连接时直接返回，调用方需持有 lock
+func connect(name string) (*gorm.DB, error) {
+	if gormDB, ok := dbMap[name]; ok {
+		return gormDB, nil
+	}
+	mysqlConfig, err := GetNamedConfig(name)
+	if nil != err {
+		return nil, err
+	}
+	section := config.SectionName("mysql", name)
 
 	//拼接下dsn参数, dsn格式可以参考上面的语法，这里使用Sprintf动态拼接dsn参数，因为一般数据库连接参数，我们都是保存在配置文件里面，需要从配置文件加载参数，然后拼接dsn。
 	// 参考 https://github.com/go-sql-driver/mysql#dsn-data-source-name 获取详情
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&collation=utf8mb4_unicode_ci&parseTime=True&loc=Local&timeout=%s", username, password, host, port, dbname, timeout)
-
-	var err error
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&collation=utf8mb4_unicode_ci&parseTime=True&loc=Local&timeout=%s",
+		mysqlConfig.Username, mysqlConfig.Password, mysqlConfig.Host, mysqlConfig.Port, mysqlConfig.Dbname, mysqlConfig.Timeout)
 
 	logLevel := logger.Silent
 	if core.Mode == core.Dev {
 		logLevel = logger.Info
 	}
 
-	db, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
+	gormDB, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
 		Logger: logger.New(
 			log.New(os.Stdout, "\r\n", log.LstdFlags), // io writer
 			logger.Config{
@@ -50,25 +130,127 @@ func init() {
 	})
 
 	if nil != err {
-		panic(dError.NewError("连接数据库出错", err))
+		return nil, dError.NewError(fmt.Sprintf("连接数据库 [%s] 出错", section), err)
 	}
 	// 控制数据库连接池
-	sqlDB, err := db.DB()
+	sqlDB, err := gormDB.DB()
 
 	if nil != err {
-		panic(dError.NewError("数据库连接池错误", err))
+		return nil, dError.NewError(fmt.Sprintf("数据库 [%s] 连接池错误", section), err)
+	}
+
+	setPool(sqlDB, mysqlConfig)
+
+	dbMap[name] = gormDB
+	// 热加载时新配置同样需要通过校验
+	config.Register(section, &ConfigType{})
+	if "" != name {
+		config.OnChange(section, reloadPool)
+	}
+	core.RegisterHealthCheck(healthCheckName(name), func(ctx context.Context) (any, error) {
+		return healthCheck(ctx, name)
+	})
+	return gormDB, nil
+}
+
+// healthCheckName 默认实例为 mysql，命名实例为 mysql.<name>
+func healthCheckName(name string) string {
+	if "" == name {
+		return core.ComponentMysql
 	}
+	return core.ComponentMysql + "." + name
+}
 
+// setPool 设置连接池参数
+func setPool(sqlDB *sql.DB, mysqlConfig *ConfigType) {
 	// SetMaxIdleConns 设置空闲连接池中连接的最大数量
-	sqlDB.SetMaxIdleConns(10)
+	sqlDB.SetMaxIdleConns(mysqlConfig.MaxIdleConns)
 
 	// SetMaxOpenConns 设置打开数据库连接的最大数量。
-	sqlDB.SetMaxOpenConns(100)
+	sqlDB.SetMaxOpenConns(mysqlConfig.MaxOpenConns)
 
 	// SetConnMaxLifetime 设置了连接可复用的最大时间。
-	sqlDB.SetConnMaxLifetime(time.Hour)
+	sqlDB.SetConnMaxLifetime(mysqlConfig.ConnMaxLifetime)
+}
+
+// reloadPool 配置热加载后更新各实例的连接池参数，连接参数的修改需要重启后生效
+func reloadPool() {
+	lock.Lock()
+	defer lock.Unlock()
+	for name, gormDB := range dbMap {
+		mysqlConfig, err := GetNamedConfig(name)
+		if nil != err {
+			continue
+		}
+		sqlDB, err := gormDB.DB()
+		if nil != err {
+			continue
+		}
+		setPool(sqlDB, mysqlConfig)
+	}
+}
+
+// healthCheck 检查数据库连通性并返回连接池统计
+func healthCheck(ctx context.Context, name string) (any, error) {
+	lock.Lock()
+	gormDB := dbMap[name]
+	lock.Unlock()
+	if nil == gormDB {
+		return nil, errors.New("数据库连接已关闭")
+	}
+	sqlDB, err := gormDB.DB()
+	if nil != err {
+		return nil, err
+	}
+	stats := sqlDB.Stats()
+	detail := map[string]any{
+		"max_open":        stats.MaxOpenConnections,
+		"open":            stats.OpenConnections,
+		"in_use":          stats.InUse,
+		"idle":            stats.Idle,
+		"wait_count":      stats.WaitCount,
+		"wait_duration":   stats.WaitDuration.String(),
+		"max_idle_closed": stats.MaxIdleClosed,
+	}
+	return detail, sqlDB.PingContext(ctx)
+}
+
+// Close 关闭全部实例的连接池
+func Close(ctx context.Context) error {
+	lock.Lock()
+	defer lock.Unlock()
+	var errList []error
+	for name, gormDB := range dbMap {
+		delete(dbMap, name)
+		sqlDB, err := gormDB.DB()
+		if nil == err {
+			err = sqlDB.Close()
+		}
+		if nil != err {
+			errList = append(errList, err)
+		}
+	}
+	if 0 < len(errList) {
+		return dError.NewError("关闭数据库连接出错", errList...)
+	}
+	return nil
 }
 
+// GetInstance 获取默认实例 [mysql] 的连接，未连接时自动连接，连接失败会 panic
 func GetInstance() *gorm.DB {
-	return db
+	return Get("")
+}
+
+// Get 获取命名实例 [mysql.<name>] 的连接，name 为空时为默认实例；未连接时自动连接，连接失败会 panic
+func Get(name string) *gorm.DB {
+	lock.Lock()
+	defer lock.Unlock()
+	if err := config.Load(); nil != err {
+		panic(err)
+	}
+	gormDB, err := connect(name)
+	if nil != err {
+		panic(err)
+	}
+	return gormDB
 }
@@ -0,0 +1,159 @@
+package dbManager
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mini-tiger/fast-api/dError"
+	"gorm.io/gorm"
+)
+
+// MigrationType 数据库迁移，Version 按字符串排序决定执行顺序，建议使用 20060102150405 格式
+type MigrationType struct {
+	Version string
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// MigrationModelType 已执行的迁移记录
+type MigrationModelType struct {
+	Version     string `gorm:"primaryKey;size:64"`
+	Name        string `gorm:"size:255"`
+	AppliedTime string `gorm:"size:32"`
+}
+
+func (m *MigrationModelType) TableName() string {
+	return "schema_migration"
+}
+
+// MigrationStatusType 迁移状态
+type MigrationStatusType struct {
+	Version     string
+	Name        string
+	Applied     bool
+	AppliedTime string
+}
+
+var migrationLock sync.Mutex
+var migrationMap = map[string]*MigrationType{}
+
+// RegisterMigration 注册迁移，版本号重复时 panic
+func RegisterMigration(migration *MigrationType) {
+	migrationLock.Lock()
+	defer migrationLock.Unlock()
+	if _, ok := migrationMap[migration.Version]; ok {
+		panic(dError.NewError(fmt.Sprintf("迁移版本 %s 重复注册", migration.Version)))
+	}
+	migrationMap[migration.Version] = migration
+}
+
+// migrationList 按版本排序的全部迁移
+func migrationList() []*MigrationType {
+	migrationLock.Lock()
+	defer migrationLock.Unlock()
+	list := make([]*MigrationType, 0, len(migrationMap))
+	for _, migration := range migrationMap {
+		list = append(list, migration)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Version < list[j].Version
+	})
+	return list
+}
+
+// appliedMigrations 已执行的迁移
+func appliedMigrations() (map[string]MigrationModelType, error) {
+	if err := GetInstance().AutoMigrate(&MigrationModelType{}); nil != err {
+		return nil, dError.NewError("创建迁移记录表失败", err)
+	}
+	var modelList []MigrationModelType
+	if err := GetInstance().Find(&modelList).Error; nil != err {
+		return nil, dError.NewError("读取迁移记录失败", err)
+	}
+	appliedMap := make(map[string]MigrationModelType, len(modelList))
+	for _, model := range modelList {
+		appliedMap[model.Version] = model
+	}
+	return appliedMap, nil
+}
+
+// MigrateUp 按版本顺序执行全部未执行的迁移，每个迁移在单独的事务中执行，返回执行的版本
+func MigrateUp() ([]string, error) {
+	appliedMap, err := appliedMigrations()
+	if nil != err {
+		return nil, err
+	}
+	var versionList []string
+	for _, migration := range migrationList() {
+		if _, ok := appliedMap[migration.Version]; ok {
+			continue
+		}
+		err := GetInstance().Transaction(func(tx *gorm.DB) error {
+			if err := migration.Up(tx); nil != err {
+				return err
+			}
+			return tx.Create(&MigrationModelType{
+				Version:     migration.Version,
+				Name:        migration.Name,
+				AppliedTime: time.Now().Format("2006-01-02 15:04:05"),
+			}).Error
+		})
+		if nil != err {
+			return versionList, dError.NewError(fmt.Sprintf("迁移 %s %s 执行失败", migration.Version, migration.Name), err)
+		}
+		versionList = append(versionList, migration.Version)
+	}
+	return versionList, nil
+}
+
+// MigrateDown 按版本倒序回滚最近执行的 steps 个迁移，返回回滚的版本
+func MigrateDown(steps int) ([]string, error) {
+	appliedMap, err := appliedMigrations()
+	if nil != err {
+		return nil, err
+	}
+	list := migrationList()
+	var versionList []string
+	for i := len(list) - 1; i >= 0 && len(versionList) < steps; i-- {
+		migration := list[i]
+		if _, ok := appliedMap[migration.Version]; !ok {
+			continue
+		}
+		if nil == migration.Down {
+			return versionList, dError.NewError(fmt.Sprintf("迁移 %s %s 不支持回滚", migration.Version, migration.Name))
+		}
+		err := GetInstance().Transaction(func(tx *gorm.DB) error {
+			if err := migration.Down(tx); nil != err {
+				return err
+			}
+			return tx.Delete(&MigrationModelType{Version: migration.Version}).Error
+		})
+		if nil != err {
+			return versionList, dError.NewError(fmt.Sprintf("迁移 %s %s 回滚失败", migration.Version, migration.Name), err)
+		}
+		versionList = append(versionList, migration.Version)
+	}
+	return versionList, nil
+}
+
+// MigrateStatus 全部迁移的执行状态
+func MigrateStatus() ([]MigrationStatusType, error) {
+	appliedMap, err := appliedMigrations()
+	if nil != err {
+		return nil, err
+	}
+	var statusList []MigrationStatusType
+	for _, migration := range migrationList() {
+		model, applied := appliedMap[migration.Version]
+		statusList = append(statusList, MigrationStatusType{
+			Version:     migration.Version,
+			Name:        migration.Name,
+			Applied:     applied,
+			AppliedTime: model.AppliedTime,
+		})
+	}
+	return statusList, nil
+}
@@ -0,0 +1,67 @@
+package dbManager
+
+import (
+	"context"
+
+	"github.com/mini-tiger/fast-api/config"
+	"github.com/mini-tiger/fast-api/core"
+	"gorm.io/gorm"
+)
+
+// ConfigKvModelType 远程配置，[config] remote = mysql 时覆盖文件配置
+// Server、Mode 为空时对全部服务、环境生效，指定时优先
+type ConfigKvModelType struct {
+	Id         int    `gorm:"primaryKey"`
+	Server     string `gorm:"size:64;uniqueIndex:uk_config_kv"`
+	Mode       string `gorm:"size:32;uniqueIndex:uk_config_kv"`
+	Section    string `gorm:"size:128;uniqueIndex:uk_config_kv"`
+	Name       string `gorm:"size:128;uniqueIndex:uk_config_kv"`
+	Value      string `gorm:"type:text"`
+	UpdateTime string `gorm:"size:32"`
+}
+
+func (m *ConfigKvModelType) TableName() string {
+	return "config_kv"
+}
+
+func init() {
+	RegisterMigration(&MigrationType{
+		Version: "20261017010000",
+		Name:    "创建远程配置 config_kv 表",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&ConfigKvModelType{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&ConfigKvModelType{})
+		},
+	})
+	config.RegisterRemoteSource("mysql", fetchRemote)
+}
+
+// fetchRemote 读取当前服务、环境的远程配置，key 为服务名称，为空时使用 [core] serverName
+func fetchRemote(ctx context.Context, key string) ([]config.ValueSourceType, error) {
+	if err := Init(); nil != err {
+		return nil, err
+	}
+	if "" == key {
+		coreConfig, err := config.GetCoreConfig()
+		if nil != err {
+			return nil, err
+		}
+		key = coreConfig.ServerName
+	}
+	var modelList []ConfigKvModelType
+	// 按 Server、Mode 升序，指定服务、环境的配置排在后面覆盖通用配置
+	err := GetInstance().WithContext(ctx).
+		Where("server IN ? AND mode IN ?", []string{"", key}, []string{"", string(core.Mode)}).
+		Order("server, mode, id").
+		Find(&modelList).Error
+	if nil != err {
+		return nil, err
+	}
+	valueList := make([]config.ValueSourceType, 0, len(modelList))
+	for _, model := range modelList {
+		valueList = append(valueList, config.ValueSourceType{Section: model.Section, Key: model.Name, Value: model.Value})
+	}
+	return valueList, nil
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/mini-tiger/fast-api/config"
+	"github.com/mini-tiger/fast-api/dError"
+)
+
+// 管理路由，配置 [http] adminToken 后启用，请求需携带 Authorization: Bearer <adminToken>
+const (
+	AdminPrefix     = "/admin"
+	AdminConfigPath = "/config"
+)
+
+var adminLock sync.RWMutex
+
+// adminToken 管理路由的访问令牌，为空时管理路由返回 404
+var adminToken string
+
+var admin = router.Group(AdminPrefix, adminAuth())
+
+// Admin 获取管理路由分组，其他组件可在此注册运维接口
+func Admin() *RouterType {
+	return admin
+}
+
+// adminAuth 校验访问令牌，未配置令牌时视为管理路由不存在
+func adminAuth() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *ContextType) error {
+			adminLock.RLock()
+			token := adminToken
+			adminLock.RUnlock()
+			if "" == token {
+				return dError.NewError(http.StatusText(http.StatusNotFound)).SetHttpStatus(http.StatusNotFound)
+			}
+			requestToken := strings.TrimPrefix(c.Request.Header.Get("Authorization"), "Bearer ")
+			if 1 != subtle.ConstantTimeCompare([]byte(token), []byte(requestToken)) {
+				return dError.NewError(http.StatusText(http.StatusUnauthorized)).SetHttpStatus(http.StatusUnauthorized)
+			}
+			return next(c)
+		}
+	}
+}
+
+// AdminConfig 返回当前生效的配置及来源，敏感配置已隐藏
+func AdminConfig(c *ContextType) error {
+	valueList := config.Explain()
+	for i := range valueList {
+		valueList[i].Value = config.Redact(valueList[i].Key, valueList[i].Value)
+	}
+	return c.Success(valueList)
+}
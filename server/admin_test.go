@@ -0,0 +1,39 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminAuth(t *testing.T) {
+	r := NewRouter()
+	r.Group(AdminPrefix, adminAuth()).GET("/ping", func(c *ContextType) error {
+		return c.String(http.StatusOK, "pong")
+	})
+	request := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, AdminPrefix+"/ping", nil)
+		if "" != token {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// 未配置令牌时管理路由不可用
+	if http.StatusNotFound != request("any") {
+		t.Fatal("未配置令牌时应返回 404")
+	}
+
+	adminToken = "t0ken"
+	defer func() {
+		adminToken = ""
+	}()
+	if http.StatusUnauthorized != request("") || http.StatusUnauthorized != request("wrong") {
+		t.Fatal("令牌错误时应返回 401")
+	}
+	if http.StatusOK != request("t0ken") {
+		t.Fatal("令牌正确时应返回 200")
+	}
+}
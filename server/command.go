@@ -0,0 +1,20 @@
+package server
+
+import (
+	"github.com/mini-tiger/fast-api/command"
+	"github.com/mini-tiger/fast-api/core"
+)
+
+func init() {
+	command.Register("serve", &command.CommandType{
+		Usage: "初始化全部组件并启动服务，收到 SIGINT/SIGTERM 后退出",
+		Run: func(c *command.ContextType) error {
+			app, err := core.Bootstrap(core.OptionsType{})
+			if nil != err {
+				_ = app.Shutdown()
+				return err
+			}
+			return app.Run()
+		},
+	})
+}
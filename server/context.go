@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+)
+
+// ContextType 单次请求的上下文
+type ContextType struct {
+	Writer  http.ResponseWriter
+	Request *http.Request
+	// values 中间件之间传递的数据
+	values map[string]any
+}
+
+// responseWriterType 记录响应状态码和字节数
+type responseWriterType struct {
+	http.ResponseWriter
+	status int
+	size   int
+}
+
+func (w *responseWriterType) WriteHeader(status int) {
+	if 0 != w.status {
+		return
+	}
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriterType) Write(data []byte) (int, error) {
+	if 0 == w.status {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(data)
+	w.size += n
+	return n, err
+}
+
+// Unwrap 供 http.ResponseController 获取原始 ResponseWriter
+func (w *responseWriterType) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// TraceIdHeader 请求链路ID的请求头和响应头
+const TraceIdHeader = "X-Trace-Id"
+
+func newContext(w http.ResponseWriter, r *http.Request) *ContextType {
+	return &ContextType{
+		Writer:  &responseWriterType{ResponseWriter: w},
+		Request: r,
+	}
+}
+
+// TraceId 请求链路ID，优先使用请求头 X-Trace-Id，没有时生成并写入响应头
+func (c *ContextType) TraceId() string {
+	if traceId, ok := c.Get(TraceIdHeader); ok {
+		return traceId.(string)
+	}
+	traceId := c.Request.Header.Get(TraceIdHeader)
+	if "" == traceId {
+		traceId = newTraceId()
+	}
+	c.Set(TraceIdHeader, traceId)
+	c.Writer.Header().Set(TraceIdHeader, traceId)
+	return traceId
+}
+
+// newTraceId 生成16字节随机ID
+func newTraceId() string {
+	data := make([]byte, 16)
+	_, _ = rand.Read(data)
+	return hex.EncodeToString(data)
+}
+
+// Context 请求的 context，客户端断开或服务退出时会取消
+func (c *ContextType) Context() context.Context {
+	return c.Request.Context()
+}
+
+// Param 获取路径参数，如路由 /users/{id} 中的 id
+func (c *ContextType) Param(name string) string {
+	return c.Request.PathValue(name)
+}
+
+// Query 获取查询参数
+func (c *ContextType) Query(name string) string {
+	return c.Request.URL.Query().Get(name)
+}
+
+// BindJSON 解析 JSON 请求体
+func (c *ContextType) BindJSON(dest any) error {
+	return json.NewDecoder(c.Request.Body).Decode(dest)
+}
+
+// Set 保存中间件之间传递的数据
+func (c *ContextType) Set(key string, value any) {
+	if nil == c.values {
+		c.values = map[string]any{}
+	}
+	c.values[key] = value
+}
+
+// Get 获取中间件之间传递的数据
+func (c *ContextType) Get(key string) (any, bool) {
+	value, ok := c.values[key]
+	return value, ok
+}
+
+// Status 已写入的响应状态码，未写入时为 0
+func (c *ContextType) Status() int {
+	return c.Writer.(*responseWriterType).status
+}
+
+// Written 是否已写入响应
+func (c *ContextType) Written() bool {
+	return 0 != c.Status()
+}
+
+// JSON 输出 JSON
+func (c *ContextType) JSON(status int, data any) error {
+	c.Writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+	c.Writer.WriteHeader(status)
+	return json.NewEncoder(c.Writer).Encode(data)
+}
+
+// String 输出文本
+func (c *ContextType) String(status int, text string) error {
+	c.Writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	c.Writer.WriteHeader(status)
+	_, err := c.Writer.Write([]byte(text))
+	return err
+}
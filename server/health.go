@@ -0,0 +1,47 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/mini-tiger/fast-api/core"
+)
+
+// 健康检查路由，Kubernetes 的 livenessProbe / readinessProbe 分别调用
+const (
+	HealthzPath = "/healthz"
+	ReadyzPath  = "/readyz"
+)
+
+// healthTimeout 每项检查的超时时间，[http] healthTimeout 可配置
+var healthTimeout = 3 * time.Second
+
+// Healthz 存活检查：进程未处于退出中即返回 200，报告仅供参考，依赖异常不会导致重启
+func Healthz(c *ContextType) error {
+	report := core.CheckHealth(c.Context(), healthTimeout)
+	status := http.StatusOK
+	if stopping() {
+		status = http.StatusServiceUnavailable
+		report.Status = core.HealthDown
+	}
+	return c.JSON(status, report)
+}
+
+// Readyz 就绪检查：任一检查失败或正在退出时返回 503，流量会被摘除
+func Readyz(c *ContextType) error {
+	report := core.CheckHealth(c.Context(), healthTimeout)
+	status := http.StatusOK
+	if stopping() {
+		report.Status = core.HealthDown
+	}
+	if core.HealthUp != report.Status {
+		status = http.StatusServiceUnavailable
+	}
+	return c.JSON(status, report)
+}
+
+// stopping 应用是否正在退出
+func stopping() bool {
+	app := core.GetApp()
+	return nil != app && app.Stopping()
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/mini-tiger/fast-api/dLogger"
+)
+
+// AccessLogType 访问日志内容
+type AccessLogType struct {
+	TraceId  string `json:"trace_id"`
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+	Query    string `json:"query,omitempty"`
+	Status   int    `json:"status"`
+	Size     int    `json:"size"`
+	Duration string `json:"duration"`
+	ClientIp string `json:"client_ip"`
+	Error    string `json:"error,omitempty"`
+}
+
+// skipAccessLogPathMap 不记录访问日志的路径，探针请求过于频繁
+var skipAccessLogPathMap = map[string]bool{
+	HealthzPath: true,
+	ReadyzPath:  true,
+}
+
+// AccessLog 通过 dLogger 记录访问日志，5xx 记为 error，4xx 记为 warning
+func AccessLog() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *ContextType) error {
+			if skipAccessLogPathMap[c.Request.URL.Path] {
+				return next(c)
+			}
+			begin := time.Now()
+			// 在处理前生成，保证响应头中带有链路ID
+			traceId := c.TraceId()
+			err := next(c)
+			if nil != err {
+				handleError(c, err)
+			}
+
+			writer := c.Writer.(*responseWriterType)
+			logData := AccessLogType{
+				TraceId:  traceId,
+				Method:   c.Request.Method,
+				Path:     c.Request.URL.Path,
+				Query:    c.Request.URL.RawQuery,
+				Status:   writer.status,
+				Size:     writer.size,
+				Duration: time.Since(begin).String(),
+				ClientIp: clientIp(c.Request),
+			}
+			if nil != err {
+				logData.Error = err.Error()
+			}
+
+			logLevel := dLogger.LeverInfo
+			if http.StatusInternalServerError <= logData.Status {
+				logLevel = dLogger.LeverError
+			} else if http.StatusBadRequest <= logData.Status {
+				logLevel = dLogger.LeverWaning
+			}
+			dLogger.Write(logLevel, "access", logData)
+			return nil
+		}
+	}
+}
+
+// Recover 捕获处理函数中的 panic，返回 500 并记录堆栈
+func Recover() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *ContextType) (err error) {
+			defer func() {
+				if recovered := recover(); nil != recovered {
+					if http.ErrAbortHandler == recovered {
+						panic(recovered)
+					}
+					dLogger.Write(dLogger.LeverError, "panic", fmt.Sprintf("%s %s: %v\n%s", c.Request.Method, c.Request.URL.Path, recovered, debug.Stack()))
+					err = fmt.Errorf("panic: %v", recovered)
+				}
+			}()
+			return next(c)
+		}
+	}
+}
+
+// clientIp 获取客户端IP，优先使用代理转发的地址
+func clientIp(r *http.Request) string {
+	if ip := r.Header.Get("X-Real-Ip"); "" != ip {
+		return ip
+	}
+	if forwarded := r.Header.Get("X-Forwarded-For"); "" != forwarded {
+		ip, _, _ := strings.Cut(forwarded, ",")
+		return strings.TrimSpace(ip)
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if nil != err {
+		return r.RemoteAddr
+	}
+	return host
+}
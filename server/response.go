@@ -0,0 +1,75 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/mini-tiger/fast-api/dError"
+	"github.com/mini-tiger/fast-api/dLogger"
+)
+
+// CodeSuccess 成功时的业务码
+const CodeSuccess = 0
+
+// ResponseType 统一响应结构
+type ResponseType struct {
+	Code    int    `json:"code"`
+	Msg     string `json:"msg"`
+	Data    any    `json:"data"`
+	TraceId string `json:"trace_id"`
+}
+
+// errorLogType 错误日志内容，包含不返回给客户端的原始错误
+type errorLogType struct {
+	TraceId   string   `json:"trace_id"`
+	Method    string   `json:"method"`
+	Path      string   `json:"path"`
+	Code      int      `json:"code"`
+	UserMag   string   `json:"user_msg"`
+	SourceErr []string `json:"source_err"`
+}
+
+// Success 返回成功响应
+func (c *ContextType) Success(data any) error {
+	return c.JSON(http.StatusOK, ResponseType{
+		Code:    CodeSuccess,
+		Msg:     "success",
+		Data:    data,
+		TraceId: c.TraceId(),
+	})
+}
+
+// Fail 返回错误响应
+// *dError.ErrorType 按其 HTTP 状态码和业务码返回 UserMag，SourceErr 只写入日志；
+// 其他错误统一返回 500，错误内容只写入日志
+func (c *ContextType) Fail(err error) error {
+	var dErr *dError.ErrorType
+	if !errors.As(err, &dErr) {
+		dErr = dError.NewError(http.StatusText(http.StatusInternalServerError), err).
+			SetHttpStatus(http.StatusInternalServerError)
+	}
+	content := dErr.GetContent()
+
+	if 0 < len(content.SourceErr) {
+		logData := errorLogType{
+			TraceId: c.TraceId(),
+			Method:  c.Request.Method,
+			Path:    c.Request.URL.Path,
+			Code:    content.GetCode(),
+			UserMag: content.UserMag,
+		}
+		for _, sourceErr := range content.SourceErr {
+			if nil != sourceErr {
+				logData.SourceErr = append(logData.SourceErr, sourceErr.Error())
+			}
+		}
+		dLogger.Write(dLogger.LeverError, "http", logData)
+	}
+
+	return c.JSON(content.GetHttpStatus(), ResponseType{
+		Code:    content.GetCode(),
+		Msg:     content.UserMag,
+		Data:    nil,
+		TraceId: c.TraceId(),
+	})
+}
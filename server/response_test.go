@@ -0,0 +1,58 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mini-tiger/fast-api/dError"
+)
+
+func TestFail(t *testing.T) {
+	r := NewRouter()
+	r.GET("/business", func(c *ContextType) error {
+		return dError.NewError("余额不足", errors.New("balance=0 sql=select ...")).
+			SetCode(10001).SetHttpStatus(http.StatusBadRequest)
+	})
+	r.GET("/internal", func(c *ContextType) error {
+		return errors.New("dial tcp 10.0.0.1:3306: connect refused")
+	})
+	r.GET("/success", func(c *ContextType) error {
+		return c.Success(map[string]int{"id": 1})
+	})
+
+	caseList := []struct {
+		path   string
+		status int
+		code   int
+		msg    string
+	}{
+		{"/business", http.StatusBadRequest, 10001, "余额不足"},
+		{"/internal", http.StatusInternalServerError, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError)},
+		{"/success", http.StatusOK, CodeSuccess, "success"},
+	}
+	for _, c := range caseList {
+		req := httptest.NewRequest(http.MethodGet, c.path, nil)
+		req.Header.Set(TraceIdHeader, "trace-1")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if c.status != w.Code {
+			t.Errorf("%s: 状态码期望 %d 实际 %d", c.path, c.status, w.Code)
+		}
+		body := w.Body.String()
+		if strings.Contains(body, "sql=") || strings.Contains(body, "3306") {
+			t.Errorf("%s: 原始错误不应返回给客户端: %s", c.path, body)
+		}
+		response := ResponseType{}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); nil != err {
+			t.Fatalf("%s: %v", c.path, err)
+		}
+		if c.code != response.Code || c.msg != response.Msg || "trace-1" != response.TraceId {
+			t.Errorf("%s: %+v", c.path, response)
+		}
+	}
+}
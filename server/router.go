@@ -0,0 +1,131 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// HandlerFunc 请求处理函数，返回的错误由路由统一处理
+type HandlerFunc func(c *ContextType) error
+
+// MiddlewareFunc 中间件，包装下一个处理函数
+type MiddlewareFunc func(next HandlerFunc) HandlerFunc
+
+// RouterType 路由，根路由和分组共用同一个 ServeMux
+// 路由规则使用标准库 ServeMux 的语法，如 GET /users/{id}
+type RouterType struct {
+	mux    *http.ServeMux
+	parent *RouterType
+	prefix string
+	lock   *sync.RWMutex
+	// middlewareList 本分组的中间件，请求时与上级分组的中间件组合，注册路由后再 Use 也会生效
+	middlewareList []MiddlewareFunc
+}
+
+// NewRouter 创建根路由
+func NewRouter() *RouterType {
+	return &RouterType{
+		mux:  http.NewServeMux(),
+		lock: &sync.RWMutex{},
+	}
+}
+
+// Use 添加中间件，按添加顺序由外到内执行
+func (r *RouterType) Use(middlewareList ...MiddlewareFunc) *RouterType {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.middlewareList = append(r.middlewareList, middlewareList...)
+	return r
+}
+
+// Group 创建路由分组，分组继承上级的前缀和中间件
+func (r *RouterType) Group(prefix string, middlewareList ...MiddlewareFunc) *RouterType {
+	return &RouterType{
+		mux:            r.mux,
+		parent:         r,
+		prefix:         joinPath(r.prefix, prefix),
+		lock:           r.lock,
+		middlewareList: middlewareList,
+	}
+}
+
+func (r *RouterType) GET(path string, handler HandlerFunc) {
+	r.Handle(http.MethodGet, path, handler)
+}
+
+func (r *RouterType) POST(path string, handler HandlerFunc) {
+	r.Handle(http.MethodPost, path, handler)
+}
+
+func (r *RouterType) PUT(path string, handler HandlerFunc) {
+	r.Handle(http.MethodPut, path, handler)
+}
+
+func (r *RouterType) PATCH(path string, handler HandlerFunc) {
+	r.Handle(http.MethodPatch, path, handler)
+}
+
+func (r *RouterType) DELETE(path string, handler HandlerFunc) {
+	r.Handle(http.MethodDelete, path, handler)
+}
+
+// Handle 注册路由，method 为空时匹配全部请求方法；路由冲突时 panic
+func (r *RouterType) Handle(method, path string, handler HandlerFunc) {
+	pattern := joinPath(r.prefix, path)
+	if "" != method {
+		pattern = fmt.Sprintf("%s %s", method, pattern)
+	}
+	r.mux.HandleFunc(pattern, func(w http.ResponseWriter, req *http.Request) {
+		c := newContext(w, req)
+		if err := r.chain(handler)(c); nil != err {
+			handleError(c, err)
+		}
+	})
+}
+
+// HandleHTTP 注册标准库的 http.Handler，同样经过中间件
+func (r *RouterType) HandleHTTP(method, path string, handler http.Handler) {
+	r.Handle(method, path, func(c *ContextType) error {
+		handler.ServeHTTP(c.Writer, c.Request)
+		return nil
+	})
+}
+
+// ServeHTTP 实现 http.Handler
+func (r *RouterType) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mux.ServeHTTP(w, req)
+}
+
+// chain 由内到外组合本分组及所有上级分组的中间件
+func (r *RouterType) chain(handler HandlerFunc) HandlerFunc {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	for group := r; nil != group; group = group.parent {
+		for i := len(group.middlewareList) - 1; i >= 0; i-- {
+			handler = group.middlewareList[i](handler)
+		}
+	}
+	return handler
+}
+
+// handleError 处理函数返回错误且尚未写入响应时，按统一响应结构返回
+func handleError(c *ContextType, err error) {
+	if c.Written() {
+		return
+	}
+	_ = c.Fail(err)
+}
+
+// joinPath 拼接分组前缀和路径
+func joinPath(prefix, path string) string {
+	if "" != path && !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	full := strings.TrimRight(prefix, "/") + path
+	if "" == full {
+		return "/"
+	}
+	return full
+}
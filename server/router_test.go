@@ -0,0 +1,69 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRouterGroup(t *testing.T) {
+	var trace []string
+	mark := func(name string) MiddlewareFunc {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(c *ContextType) error {
+				trace = append(trace, name)
+				return next(c)
+			}
+		}
+	}
+
+	r := NewRouter()
+	api := r.Group("/api", mark("api"))
+	api.GET("/users/{id}", func(c *ContextType) error {
+		return c.String(http.StatusOK, "user "+c.Param("id"))
+	})
+	api.POST("/fail", func(c *ContextType) error {
+		return errors.New("boom")
+	})
+	// 注册路由之后添加的中间件同样生效
+	r.Use(mark("root"))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/users/10", nil))
+	if http.StatusOK != w.Code || "user 10" != w.Body.String() {
+		t.Fatalf("%d %s", w.Code, w.Body.String())
+	}
+	if "root,api" != strings.Join(trace, ",") {
+		t.Fatalf("中间件顺序错误: %v", trace)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/fail", nil))
+	if http.StatusMethodNotAllowed != w.Code {
+		t.Fatalf("请求方法不匹配应返回 405，实际 %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/fail", nil))
+	if http.StatusInternalServerError != w.Code {
+		t.Fatalf("返回错误应为 500，实际 %d", w.Code)
+	}
+}
+
+func TestJoinPath(t *testing.T) {
+	caseList := [][3]string{
+		{"", "", "/"},
+		{"", "/", "/"},
+		{"/api", "", "/api"},
+		{"/api/", "/users", "/api/users"},
+		{"/api", "users", "/api/users"},
+		{"/api", "/", "/api/"},
+	}
+	for _, c := range caseList {
+		if c[2] != joinPath(c[0], c[1]) {
+			t.Errorf("joinPath(%q, %q) = %q", c[0], c[1], joinPath(c[0], c[1]))
+		}
+	}
+}
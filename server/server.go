@@ -0,0 +1,131 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mini-tiger/fast-api/config"
+	"github.com/mini-tiger/fast-api/core"
+	"github.com/mini-tiger/fast-api/dError"
+	"github.com/mini-tiger/fast-api/dLogger"
+)
+
+var router = NewRouter()
+var httpServer *http.Server
+var lock sync.Mutex
+
+// ConfigType [http] 配置
+type ConfigType struct {
+	// Addr 监听地址
+	Addr              string        `ini:"addr" default:":8080"`
+	ReadTimeout       time.Duration `ini:"readTimeout" default:"30s" range:"0s,"`
+	ReadHeaderTimeout time.Duration `ini:"readHeaderTimeout" default:"10s" range:"0s,"`
+	WriteTimeout      time.Duration `ini:"writeTimeout" default:"30s" range:"0s,"`
+	IdleTimeout       time.Duration `ini:"idleTimeout" default:"120s" range:"0s,"`
+	// HealthTimeout 健康检查每项的超时时间
+	HealthTimeout time.Duration `ini:"healthTimeout" default:"3s" range:"1ms,"`
+	// AccessLog 是否记录访问日志
+	AccessLog bool `ini:"accessLog" default:"true"`
+	// AdminToken 管理路由的访问令牌，为空时不启用管理路由
+	AdminToken string `ini:"adminToken"`
+}
+
+// GetConfig 读取并校验 [http] 配置
+func GetConfig() (*ConfigType, error) {
+	httpConfig := &ConfigType{}
+	return httpConfig, config.Bind("http", httpConfig)
+}
+
+func init() {
+	core.RegisterComponent(&core.ComponentType{
+		Name:    core.ComponentHttp,
+		Order:   70,
+		Depends: []string{core.ComponentConfig},
+		Validate: func() error {
+			_, err := GetConfig()
+			return err
+		},
+		Init:  Init,
+		Start: Start,
+		Stop:  Stop,
+	})
+}
+
+// GetInstance 获取默认路由，Start 时由 HTTP 服务监听
+func GetInstance() *RouterType {
+	return router
+}
+
+// Init 按 [http] 配置创建 HTTP 服务，已创建时直接返回
+func Init() error {
+	lock.Lock()
+	defer lock.Unlock()
+	if nil != httpServer {
+		return nil
+	}
+	httpConfig, err := GetConfig()
+	if nil != err {
+		return err
+	}
+	healthTimeout = httpConfig.HealthTimeout
+	adminLock.Lock()
+	adminToken = httpConfig.AdminToken
+	adminLock.Unlock()
+
+	// 内置中间件放在最外层，依次为访问日志、panic 捕获
+	builtinList := []MiddlewareFunc{Recover()}
+	if httpConfig.AccessLog {
+		builtinList = append([]MiddlewareFunc{AccessLog()}, builtinList...)
+	}
+	router.lock.Lock()
+	router.middlewareList = append(builtinList, router.middlewareList...)
+	router.lock.Unlock()
+
+	router.GET(HealthzPath, Healthz)
+	router.GET(ReadyzPath, Readyz)
+	router.GET(VersionPath, Version)
+	admin.GET(AdminConfigPath, AdminConfig)
+
+	httpServer = &http.Server{
+		Addr:              httpConfig.Addr,
+		Handler:           router,
+		ReadTimeout:       httpConfig.ReadTimeout,
+		ReadHeaderTimeout: httpConfig.ReadHeaderTimeout,
+		WriteTimeout:      httpConfig.WriteTimeout,
+		IdleTimeout:       httpConfig.IdleTimeout,
+	}
+	return nil
+}
+
+// Start 监听端口并在后台处理请求，端口被占用等错误会直接返回
+func Start() error {
+	if err := Init(); nil != err {
+		return err
+	}
+	listener, err := net.Listen("tcp", httpServer.Addr)
+	if nil != err {
+		return dError.NewError(fmt.Sprintf("监听 %s 失败", httpServer.Addr), err)
+	}
+	fmt.Printf("HTTP服务：%s\n", listener.Addr())
+	go func() {
+		if err := httpServer.Serve(listener); nil != err && !errors.Is(err, http.ErrServerClosed) {
+			dLogger.Write(dLogger.LeverError, "http", err)
+		}
+	}()
+	return nil
+}
+
+// Stop 停止接收新请求，并等待处理中的请求结束或 ctx 超时
+func Stop(ctx context.Context) error {
+	lock.Lock()
+	defer lock.Unlock()
+	if nil == httpServer {
+		return nil
+	}
+	return httpServer.Shutdown(ctx)
+}
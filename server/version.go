@@ -0,0 +1,11 @@
+package server
+
+import "github.com/mini-tiger/fast-api/core"
+
+// VersionPath 构建信息路由
+const VersionPath = "/version"
+
+// Version 返回构建信息，用于确认线上运行的版本
+func Version(c *ContextType) error {
+	return c.Success(core.GetBuildInfo())
+}